@@ -35,6 +35,129 @@ const docTemplate = `{
                 }
             }
         },
+        "/api/stats/driver": {
+            "get": {
+                "description": "this endpoint provides getting the current state of the pull of the browser's tabs",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Service-Info"
+                ],
+                "summary": "driver's stats getting",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/entities.DriverStats"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/stats/metrics": {
+            "get": {
+                "description": "this endpoint provides getting the service's metrics: the schema's drift of the markets and the async jobs' queue",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Service-Info"
+                ],
+                "summary": "service's metrics getting",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "object"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/jobs/{id}": {
+            "get": {
+                "description": "this endpoint provides the state of the async request's job and its result",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Async-Jobs"
+                ],
+                "summary": "async job's state getting",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "the job's ID returned by the async request",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/chttp.JobResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/chttp.ResponseErr"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "this endpoint cancels the queued or the running async job: the terminal cancelled message is sent through the kafka",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Async-Jobs"
+                ],
+                "summary": "async job's cancelling",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "the job's ID returned by the async request",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "the job was cancelled",
+                        "schema": {
+                            "$ref": "#/definitions/chttp.JobResponse"
+                        }
+                    },
+                    "202": {
+                        "description": "the running job is being cancelled",
+                        "schema": {
+                            "$ref": "#/definitions/chttp.JobResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/chttp.ResponseErr"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/chttp.ResponseErr"
+                        }
+                    }
+                }
+            }
+        },
         "/products/filter/markets": {
             "get": {
                 "description": "this endpoint provides filtering products from marketplaces without any specified filtration",
@@ -89,7 +212,8 @@ const docTemplate = `{
                             "popular",
                             "pricedown",
                             "priceup",
-                            "newly"
+                            "newly",
+                            "rate"
                         ],
                         "type": "string",
                         "default": "popular",
@@ -110,12 +234,47 @@ const docTemplate = `{
                     },
                     {
                         "enum": [
-                            "min",
-                            "max"
+                            "in_stock",
+                            "all",
+                            "preorder"
+                        ],
+                        "type": "string",
+                        "default": "in_stock",
+                        "description": "the stock status of the sample's products",
+                        "name": "availability",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            0,
+                            1
                         ],
+                        "type": "integer",
+                        "default": 0,
+                        "description": "the flag that defines 'Should the samples be merged in one list?': it's sorted by the price for pricedown and priceup, the rest sorts interleave the products by their positions in the markets' samples",
+                        "name": "merge",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 300,
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "the amount of the products that are collected from every market through the consecutive pages",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
                         "type": "string",
-                        "default": "min",
-                        "description": "the amount of the products in response's sample",
+                        "description": "the cursor of the previous response that continues it",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 100,
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 15,
+                        "description": "the amount of the products in response's sample (min and max are the aliases of 15 and 100)",
                         "name": "amount",
                         "in": "query"
                     }
@@ -142,22 +301,22 @@ const docTemplate = `{
                     "502": {
                         "description": "Bad Gateway",
                         "schema": {
-                            "$ref": "#/definitions/chttp.ResponseErr"
+                            "$ref": "#/definitions/chttp.ProductsErr"
                         }
                     }
                 }
             }
         },
-        "/products/filter/price/best-price": {
-            "get": {
-                "description": "this endpoint provides filtering products from marketplaces with the best and minimum price",
+        "/products/filter/markets/async": {
+            "post": {
+                "description": "this endpoint provides the products from the requested marketplaces without the price filters in async mode",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Price-Filters"
+                    "Common-Filters"
                 ],
-                "summary": "best price filtering",
+                "summary": "async markets filtering",
                 "parameters": [
                     {
                         "minLength": 1,
@@ -202,7 +361,8 @@ const docTemplate = `{
                             "popular",
                             "pricedown",
                             "priceup",
-                            "newly"
+                            "newly",
+                            "rate"
                         ],
                         "type": "string",
                         "default": "popular",
@@ -223,21 +383,77 @@ const docTemplate = `{
                     },
                     {
                         "enum": [
-                            "min",
-                            "max"
+                            "in_stock",
+                            "all",
+                            "preorder"
+                        ],
+                        "type": "string",
+                        "default": "in_stock",
+                        "description": "the stock status of the sample's products",
+                        "name": "availability",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "high",
+                            "normal",
+                            "low"
+                        ],
+                        "type": "string",
+                        "default": "normal",
+                        "description": "the priority of the async request's job in the queue",
+                        "name": "priority",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            0,
+                            1
                         ],
+                        "type": "integer",
+                        "default": 0,
+                        "description": "the flag that defines 'Should the samples be merged in one list?': it's sorted by the price for pricedown and priceup, the rest sorts interleave the products by their positions in the markets' samples",
+                        "name": "merge",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 300,
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "the amount of the products that are collected from every market through the consecutive pages",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
                         "type": "string",
-                        "default": "min",
-                        "description": "the amount of the products in response's sample",
+                        "description": "the cursor of the previous response that continues it",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 100,
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 15,
+                        "description": "the amount of the products in response's sample (min and max are the aliases of 15 and 100)",
                         "name": "amount",
                         "in": "query"
+                    },
+                    {
+                        "description": "the headers that need to be included into the async response",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/chttp.extraHeaders"
+                        }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "202": {
+                        "description": "Accepted",
                         "schema": {
-                            "$ref": "#/definitions/chttp.ProductResponse"
+                            "$ref": "#/definitions/chttp.JobResponse"
                         }
                     },
                     "400": {
@@ -246,14 +462,26 @@ const docTemplate = `{
                             "$ref": "#/definitions/chttp.ResponseErr"
                         }
                     },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/chttp.ResponseErr"
+                        },
+                        "headers": {
+                            "Retry-After": {
+                                "type": "integer",
+                                "description": "the amount of the seconds after that the request can be repeated"
+                            }
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/chttp.ResponseErr"
                         }
                     },
-                    "502": {
-                        "description": "Bad Gateway",
+                    "503": {
+                        "description": "Service Unavailable",
                         "schema": {
                             "$ref": "#/definitions/chttp.ResponseErr"
                         }
@@ -261,16 +489,16 @@ const docTemplate = `{
                 }
             }
         },
-        "/products/filter/price/best-price/async": {
-            "post": {
-                "description": "this endpoint provides filtering products from marketplaces with the best and minimum price in async mode",
+        "/products/filter/price/best-price": {
+            "get": {
+                "description": "this endpoint provides the top cheapest products across all the requested marketplaces with the comparison of the markets' minimum prices",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "Price-Filters"
                 ],
-                "summary": "async best price filtering",
+                "summary": "best price filtering",
                 "parameters": [
                     {
                         "minLength": 1,
@@ -315,7 +543,8 @@ const docTemplate = `{
                             "popular",
                             "pricedown",
                             "priceup",
-                            "newly"
+                            "newly",
+                            "rate"
                         ],
                         "type": "string",
                         "default": "popular",
@@ -336,48 +565,98 @@ const docTemplate = `{
                     },
                     {
                         "enum": [
-                            "min",
-                            "max"
+                            "in_stock",
+                            "all",
+                            "preorder"
                         ],
                         "type": "string",
-                        "default": "min",
-                        "description": "the amount of the products in response's sample",
-                        "name": "amount",
+                        "default": "in_stock",
+                        "description": "the stock status of the sample's products",
+                        "name": "availability",
                         "in": "query"
                     },
                     {
-                        "description": "the headers that need to be included into the async response",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/chttp.extraHeaders"
-                        }
+                        "enum": [
+                            0,
+                            1
+                        ],
+                        "type": "integer",
+                        "default": 0,
+                        "description": "the flag that defines 'Should the samples be merged in one list?': it's sorted by the price for pricedown and priceup, the rest sorts interleave the products by their positions in the markets' samples",
+                        "name": "merge",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 300,
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "the amount of the products that are collected from every market through the consecutive pages",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "the cursor of the previous response that continues it",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 100,
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 10,
+                        "description": "the amount of the cheapest products across the markets",
+                        "name": "top",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 100,
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 15,
+                        "description": "the amount of the products in response's sample (min and max are the aliases of 15 and 100)",
+                        "name": "amount",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK"
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/chttp.ProductResponse"
+                        }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
                             "$ref": "#/definitions/chttp.ResponseErr"
                         }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/chttp.ResponseErr"
+                        }
+                    },
+                    "502": {
+                        "description": "Bad Gateway",
+                        "schema": {
+                            "$ref": "#/definitions/chttp.ProductsErr"
+                        }
                     }
                 }
             }
         },
-        "/products/filter/price/exact-price": {
-            "get": {
-                "description": "this endpoint provides filtering products from marketplaces with price in range (exact-price, exact-price * 1.05 (+5%))",
+        "/products/filter/price/best-price/async": {
+            "post": {
+                "description": "this endpoint provides the top cheapest products across all the requested marketplaces with the comparison of the markets' minimum prices in async mode",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "Price-Filters"
                 ],
-                "summary": "exact price filtering",
+                "summary": "async best price filtering",
                 "parameters": [
                     {
                         "minLength": 1,
@@ -392,14 +671,6 @@ const docTemplate = `{
                         "in": "query",
                         "required": true
                     },
-                    {
-                        "minimum": 1,
-                        "type": "integer",
-                        "description": "the value of exact price",
-                        "name": "price",
-                        "in": "query",
-                        "required": true
-                    },
                     {
                         "minLength": 1,
                         "type": "array",
@@ -430,7 +701,8 @@ const docTemplate = `{
                             "popular",
                             "pricedown",
                             "priceup",
-                            "newly"
+                            "newly",
+                            "rate"
                         ],
                         "type": "string",
                         "default": "popular",
@@ -445,60 +717,711 @@ const docTemplate = `{
                         ],
                         "type": "integer",
                         "default": 1,
-                        "description": "the flag that defines 'Should image links be parsed??'",
+                        "description": "the flag that defines 'Should image links be parsed?'",
                         "name": "no-image",
                         "in": "query"
                     },
                     {
                         "enum": [
-                            "min",
-                            "max"
+                            "in_stock",
+                            "all",
+                            "preorder"
                         ],
                         "type": "string",
-                        "default": "min",
-                        "description": "the amount of the products in response's sample",
-                        "name": "amount",
+                        "default": "in_stock",
+                        "description": "the stock status of the sample's products",
+                        "name": "availability",
                         "in": "query"
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/chttp.ProductResponse"
-                        }
                     },
-                    "400": {
-                        "description": "Bad Request",
-                        "schema": {
-                            "$ref": "#/definitions/chttp.ResponseErr"
-                        }
+                    {
+                        "enum": [
+                            "high",
+                            "normal",
+                            "low"
+                        ],
+                        "type": "string",
+                        "default": "normal",
+                        "description": "the priority of the async request's job in the queue",
+                        "name": "priority",
+                        "in": "query"
                     },
-                    "500": {
-                        "description": "Internal Server Error",
-                        "schema": {
-                            "$ref": "#/definitions/chttp.ResponseErr"
-                        }
+                    {
+                        "enum": [
+                            0,
+                            1
+                        ],
+                        "type": "integer",
+                        "default": 0,
+                        "description": "the flag that defines 'Should the samples be merged in one list?': it's sorted by the price for pricedown and priceup, the rest sorts interleave the products by their positions in the markets' samples",
+                        "name": "merge",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 300,
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "the amount of the products that are collected from every market through the consecutive pages",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "the cursor of the previous response that continues it",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 100,
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 10,
+                        "description": "the amount of the cheapest products across the markets",
+                        "name": "top",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 100,
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 15,
+                        "description": "the amount of the products in response's sample (min and max are the aliases of 15 and 100)",
+                        "name": "amount",
+                        "in": "query"
+                    },
+                    {
+                        "description": "the headers that need to be included into the async response",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/chttp.extraHeaders"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/chttp.JobResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/chttp.ResponseErr"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/chttp.ResponseErr"
+                        },
+                        "headers": {
+                            "Retry-After": {
+                                "type": "integer",
+                                "description": "the amount of the seconds after that the request can be repeated"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/chttp.ResponseErr"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/chttp.ResponseErr"
+                        }
+                    }
+                }
+            }
+        },
+        "/products/filter/price/exact-price": {
+            "get": {
+                "description": "this endpoint provides filtering products from marketplaces with price in the tolerance window around the exact price (exact-price, exact-price * 1.05 (+5%) by default): the products are ranked by the distance to the exact price",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Price-Filters"
+                ],
+                "summary": "exact price filtering",
+                "parameters": [
+                    {
+                        "minLength": 1,
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "ssv",
+                        "example": "iphone+11",
+                        "description": "the exact query string",
+                        "name": "query",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "the value of exact price",
+                        "name": "price",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "minimum": 0,
+                        "type": "number",
+                        "default": 5,
+                        "description": "the tolerance of the exact price: 0 is the exact match",
+                        "name": "tolerance",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "percent",
+                            "absolute"
+                        ],
+                        "type": "string",
+                        "default": "percent",
+                        "description": "the type of the tolerance: percentage or absolute",
+                        "name": "tolerance_type",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "both",
+                            "up",
+                            "down"
+                        ],
+                        "type": "string",
+                        "default": "up",
+                        "description": "the direction of the tolerance window",
+                        "name": "direction",
+                        "in": "query"
+                    },
+                    {
+                        "minLength": 1,
+                        "type": "array",
+                        "items": {
+                            "enum": [
+                                "wildberries",
+                                "megamarket"
+                            ],
+                            "type": "string"
+                        },
+                        "collectionFormat": "ssv",
+                        "example": "megamarket+wildberries",
+                        "description": "the list of the markets using for search",
+                        "name": "markets",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 1,
+                        "description": "the num of products' sample",
+                        "name": "sample",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "popular",
+                            "pricedown",
+                            "priceup",
+                            "newly",
+                            "rate"
+                        ],
+                        "type": "string",
+                        "default": "popular",
+                        "description": "the type of products' sample sorting",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            0,
+                            1
+                        ],
+                        "type": "integer",
+                        "default": 1,
+                        "description": "the flag that defines 'Should image links be parsed??'",
+                        "name": "no-image",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "in_stock",
+                            "all",
+                            "preorder"
+                        ],
+                        "type": "string",
+                        "default": "in_stock",
+                        "description": "the stock status of the sample's products",
+                        "name": "availability",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            0,
+                            1
+                        ],
+                        "type": "integer",
+                        "default": 0,
+                        "description": "the flag that defines 'Should the samples be merged in one list?': it's sorted by the price for pricedown and priceup, the rest sorts interleave the products by their positions in the markets' samples",
+                        "name": "merge",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 300,
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "the amount of the products that are collected from every market through the consecutive pages",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "the cursor of the previous response that continues it",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 100,
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 15,
+                        "description": "the amount of the products in response's sample (min and max are the aliases of 15 and 100)",
+                        "name": "amount",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/chttp.ProductResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/chttp.ResponseErr"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/chttp.ResponseErr"
+                        }
+                    },
+                    "502": {
+                        "description": "Bad Gateway",
+                        "schema": {
+                            "$ref": "#/definitions/chttp.ProductsErr"
+                        }
+                    }
+                }
+            }
+        },
+        "/products/filter/price/exact-price/async": {
+            "post": {
+                "description": "this endpoint provides the products with the exactest prices to the client's price in the tolerance window in async mode",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Price-Filters"
+                ],
+                "summary": "async exact price filtering",
+                "parameters": [
+                    {
+                        "minLength": 1,
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "ssv",
+                        "example": "iphone+11",
+                        "description": "the exact query string",
+                        "name": "query",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "the value of exact price",
+                        "name": "price",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "minimum": 0,
+                        "type": "number",
+                        "default": 5,
+                        "description": "the tolerance of the exact price: 0 is the exact match",
+                        "name": "tolerance",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "percent",
+                            "absolute"
+                        ],
+                        "type": "string",
+                        "default": "percent",
+                        "description": "the type of the tolerance: percentage or absolute",
+                        "name": "tolerance_type",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "both",
+                            "up",
+                            "down"
+                        ],
+                        "type": "string",
+                        "default": "up",
+                        "description": "the direction of the tolerance window",
+                        "name": "direction",
+                        "in": "query"
+                    },
+                    {
+                        "minLength": 1,
+                        "type": "array",
+                        "items": {
+                            "enum": [
+                                "wildberries",
+                                "megamarket"
+                            ],
+                            "type": "string"
+                        },
+                        "collectionFormat": "ssv",
+                        "example": "megamarket+wildberries",
+                        "description": "the list of the markets using for search",
+                        "name": "markets",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 1,
+                        "description": "the num of products' sample",
+                        "name": "sample",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "popular",
+                            "pricedown",
+                            "priceup",
+                            "newly",
+                            "rate"
+                        ],
+                        "type": "string",
+                        "default": "popular",
+                        "description": "the type of products' sample sorting",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            0,
+                            1
+                        ],
+                        "type": "integer",
+                        "default": 1,
+                        "description": "the flag that defines 'Should image links be parsed?'",
+                        "name": "no-image",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "in_stock",
+                            "all",
+                            "preorder"
+                        ],
+                        "type": "string",
+                        "default": "in_stock",
+                        "description": "the stock status of the sample's products",
+                        "name": "availability",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "high",
+                            "normal",
+                            "low"
+                        ],
+                        "type": "string",
+                        "default": "normal",
+                        "description": "the priority of the async request's job in the queue",
+                        "name": "priority",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            0,
+                            1
+                        ],
+                        "type": "integer",
+                        "default": 0,
+                        "description": "the flag that defines 'Should the samples be merged in one list?': it's sorted by the price for pricedown and priceup, the rest sorts interleave the products by their positions in the markets' samples",
+                        "name": "merge",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 300,
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "the amount of the products that are collected from every market through the consecutive pages",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "the cursor of the previous response that continues it",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 100,
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 15,
+                        "description": "the amount of the products in response's sample (min and max are the aliases of 15 and 100)",
+                        "name": "amount",
+                        "in": "query"
+                    },
+                    {
+                        "description": "the headers that need to be included into the async response",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/chttp.extraHeaders"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/chttp.JobResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/chttp.ResponseErr"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/chttp.ResponseErr"
+                        },
+                        "headers": {
+                            "Retry-After": {
+                                "type": "integer",
+                                "description": "the amount of the seconds after that the request can be repeated"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/chttp.ResponseErr"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/chttp.ResponseErr"
+                        }
+                    }
+                }
+            }
+        },
+        "/products/filter/price/price-range": {
+            "get": {
+                "description": "this endpoint provides filtering products from marketplaces with specified price range",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Price-Filters"
+                ],
+                "summary": "price range filtering",
+                "parameters": [
+                    {
+                        "minLength": 1,
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "ssv",
+                        "example": "iphone+11",
+                        "description": "the exact query string",
+                        "name": "query",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "minimum": 0,
+                        "type": "integer",
+                        "description": "the price range's lower bound: less than price_up",
+                        "name": "price_down",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "the price range's upper bound: more than price_down",
+                        "name": "price_up",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "minLength": 1,
+                        "type": "array",
+                        "items": {
+                            "enum": [
+                                "wildberries",
+                                "megamarket"
+                            ],
+                            "type": "string"
+                        },
+                        "collectionFormat": "ssv",
+                        "example": "megamarket+wildberries",
+                        "description": "the list of the markets using for search",
+                        "name": "markets",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 1,
+                        "description": "the num of products' sample",
+                        "name": "sample",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "popular",
+                            "pricedown",
+                            "priceup",
+                            "newly",
+                            "rate"
+                        ],
+                        "type": "string",
+                        "default": "popular",
+                        "description": "the type of products' sample sorting",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            0,
+                            1
+                        ],
+                        "type": "integer",
+                        "default": 1,
+                        "description": "the flag that defines 'Should image links be parsed?'",
+                        "name": "no-image",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "in_stock",
+                            "all",
+                            "preorder"
+                        ],
+                        "type": "string",
+                        "default": "in_stock",
+                        "description": "the stock status of the sample's products",
+                        "name": "availability",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            0,
+                            1
+                        ],
+                        "type": "integer",
+                        "default": 0,
+                        "description": "the flag that defines 'Should the samples be merged in one list?': it's sorted by the price for pricedown and priceup, the rest sorts interleave the products by their positions in the markets' samples",
+                        "name": "merge",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 300,
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "the amount of the products that are collected from every market through the consecutive pages",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "the cursor of the previous response that continues it",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 100,
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 15,
+                        "description": "the amount of the products in response's sample (min and max are the aliases of 15 and 100)",
+                        "name": "amount",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/chttp.ProductResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/chttp.ResponseErr"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/chttp.ResponseErr"
+                        }
                     },
                     "502": {
                         "description": "Bad Gateway",
                         "schema": {
-                            "$ref": "#/definitions/chttp.ResponseErr"
+                            "$ref": "#/definitions/chttp.ProductsErr"
                         }
                     }
                 }
             }
         },
-        "/products/filter/price/price-range": {
-            "get": {
-                "description": "this endpoint provides filtering products from marketplaces with specified price range",
+        "/products/filter/price/price-range/async": {
+            "post": {
+                "description": "this endpoint provides filtering products from marketplaces with specified price range in async mode",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "Price-Filters"
                 ],
-                "summary": "price range filtering",
+                "summary": "async price range filtering",
                 "parameters": [
                     {
                         "minLength": 1,
@@ -559,7 +1482,8 @@ const docTemplate = `{
                             "popular",
                             "pricedown",
                             "priceup",
-                            "newly"
+                            "newly",
+                            "rate"
                         ],
                         "type": "string",
                         "default": "popular",
@@ -580,21 +1504,77 @@ const docTemplate = `{
                     },
                     {
                         "enum": [
-                            "min",
-                            "max"
+                            "in_stock",
+                            "all",
+                            "preorder"
+                        ],
+                        "type": "string",
+                        "default": "in_stock",
+                        "description": "the stock status of the sample's products",
+                        "name": "availability",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "high",
+                            "normal",
+                            "low"
+                        ],
+                        "type": "string",
+                        "default": "normal",
+                        "description": "the priority of the async request's job in the queue",
+                        "name": "priority",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            0,
+                            1
                         ],
+                        "type": "integer",
+                        "default": 0,
+                        "description": "the flag that defines 'Should the samples be merged in one list?': it's sorted by the price for pricedown and priceup, the rest sorts interleave the products by their positions in the markets' samples",
+                        "name": "merge",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 300,
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "the amount of the products that are collected from every market through the consecutive pages",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
                         "type": "string",
-                        "default": "min",
-                        "description": "the amount of the products in response's sample",
+                        "description": "the cursor of the previous response that continues it",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 100,
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 15,
+                        "description": "the amount of the products in response's sample (min and max are the aliases of 15 and 100)",
                         "name": "amount",
                         "in": "query"
+                    },
+                    {
+                        "description": "the headers that need to be included into the async response",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/chttp.extraHeaders"
+                        }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "202": {
+                        "description": "Accepted",
                         "schema": {
-                            "$ref": "#/definitions/chttp.ProductResponse"
+                            "$ref": "#/definitions/chttp.JobResponse"
                         }
                     },
                     "400": {
@@ -603,14 +1583,26 @@ const docTemplate = `{
                             "$ref": "#/definitions/chttp.ResponseErr"
                         }
                     },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/chttp.ResponseErr"
+                        },
+                        "headers": {
+                            "Retry-After": {
+                                "type": "integer",
+                                "description": "the amount of the seconds after that the request can be repeated"
+                            }
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/chttp.ResponseErr"
                         }
                     },
-                    "502": {
-                        "description": "Bad Gateway",
+                    "503": {
+                        "description": "Service Unavailable",
                         "schema": {
                             "$ref": "#/definitions/chttp.ResponseErr"
                         }
@@ -620,14 +1612,75 @@ const docTemplate = `{
         }
     },
     "definitions": {
+        "chttp.JobResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "finished_at": {
+                    "type": "string"
+                },
+                "job_id": {
+                    "type": "string"
+                },
+                "priority": {
+                    "type": "string"
+                },
+                "result": {
+                    "$ref": "#/definitions/chttp.ProductResponse"
+                },
+                "started_at": {
+                    "type": "string"
+                },
+                "state": {
+                    "type": "string"
+                }
+            }
+        },
         "chttp.ProductResponse": {
             "type": "object",
             "properties": {
+                "best_price": {
+                    "$ref": "#/definitions/entities.BestPriceSummary"
+                },
+                "cursor": {
+                    "type": "string"
+                },
+                "products": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/entities.Product"
+                    }
+                },
                 "samples": {
                     "type": "object",
                     "additionalProperties": {
                         "$ref": "#/definitions/entities.ProductSample"
                     }
+                },
+                "statuses": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/entities.MarketStatus"
+                    }
+                }
+            }
+        },
+        "chttp.ProductsErr": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                },
+                "statuses": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/entities.MarketStatus"
+                    }
                 }
             }
         },
@@ -661,6 +1714,44 @@ const docTemplate = `{
                 }
             }
         },
+        "entities.Availability": {
+            "type": "string",
+            "enum": [
+                "in_stock",
+                "out_of_stock",
+                "preorder",
+                "unknown"
+            ],
+            "x-enum-varnames": [
+                "InStock",
+                "OutOfStock",
+                "Preorder",
+                "UnknownAvailability"
+            ]
+        },
+        "entities.BestPriceSummary": {
+            "type": "object",
+            "properties": {
+                "market": {
+                    "type": "string"
+                },
+                "markets_min_prices": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer"
+                    }
+                },
+                "min_price": {
+                    "type": "integer"
+                },
+                "spread": {
+                    "type": "integer"
+                },
+                "spread_percent": {
+                    "type": "number"
+                }
+            }
+        },
         "entities.Currency": {
             "type": "string",
             "enum": [
@@ -670,6 +1761,85 @@ const docTemplate = `{
                 "RUB"
             ]
         },
+        "entities.DriverStats": {
+            "type": "object",
+            "properties": {
+                "acquired": {
+                    "type": "integer"
+                },
+                "browsers": {
+                    "type": "integer"
+                },
+                "exhausted": {
+                    "type": "integer"
+                },
+                "idle": {
+                    "type": "integer"
+                },
+                "in_use": {
+                    "type": "integer"
+                },
+                "recycled": {
+                    "type": "integer"
+                },
+                "tabs": {
+                    "type": "integer"
+                },
+                "waiting": {
+                    "type": "integer"
+                }
+            }
+        },
+        "entities.LoadingStopReason": {
+            "type": "string",
+            "enum": [
+                "enough_cards",
+                "stable",
+                "deadline"
+            ],
+            "x-enum-varnames": [
+                "LoadingStopEnough",
+                "LoadingStopStable",
+                "LoadingStopDeadline"
+            ]
+        },
+        "entities.MarketStatus": {
+            "type": "object",
+            "properties": {
+                "discarded": {
+                    "type": "integer"
+                },
+                "elapsed_ms": {
+                    "type": "integer"
+                },
+                "error_code": {
+                    "type": "string"
+                },
+                "market": {
+                    "type": "string"
+                },
+                "status": {
+                    "$ref": "#/definitions/entities.MarketStatusType"
+                }
+            }
+        },
+        "entities.MarketStatusType": {
+            "type": "string",
+            "enum": [
+                "ok",
+                "failed",
+                "timeout",
+                "blocked",
+                "unsupported"
+            ],
+            "x-enum-varnames": [
+                "MarketStatusOK",
+                "MarketStatusFailed",
+                "MarketStatusTimeout",
+                "MarketStatusBlocked",
+                "MarketStatusUnsupported"
+            ]
+        },
         "entities.MarketView": {
             "type": "object",
             "properties": {
@@ -681,6 +1851,17 @@ const docTemplate = `{
                 }
             }
         },
+        "entities.PageLoading": {
+            "type": "object",
+            "properties": {
+                "cards": {
+                    "type": "integer"
+                },
+                "stop_reason": {
+                    "$ref": "#/definitions/entities.LoadingStopReason"
+                }
+            }
+        },
         "entities.Price": {
             "type": "object",
             "properties": {
@@ -692,21 +1873,62 @@ const docTemplate = `{
                 },
                 "discount_price": {
                     "type": "integer"
+                },
+                "range": {
+                    "$ref": "#/definitions/entities.PriceRange"
+                }
+            }
+        },
+        "entities.PriceDeviation": {
+            "type": "object",
+            "properties": {
+                "absolute": {
+                    "type": "integer"
+                },
+                "percent": {
+                    "type": "number"
+                }
+            }
+        },
+        "entities.PriceRange": {
+            "type": "object",
+            "properties": {
+                "max": {
+                    "type": "integer"
+                },
+                "min": {
+                    "type": "integer"
                 }
             }
         },
         "entities.Product": {
             "type": "object",
             "properties": {
+                "availability": {
+                    "$ref": "#/definitions/entities.Availability"
+                },
                 "brand": {
                     "type": "string"
                 },
+                "delivery": {
+                    "$ref": "#/definitions/entities.ProductDelivery"
+                },
+                "deviation": {
+                    "$ref": "#/definitions/entities.PriceDeviation"
+                },
+                "market": {
+                    "type": "string"
+                },
                 "name": {
                     "type": "string"
                 },
                 "price": {
                     "$ref": "#/definitions/entities.Price"
                 },
+                "quantity": {
+                    "description": "Quantity and Delivery are set only if the market provides them.",
+                    "type": "integer"
+                },
                 "related_links": {
                     "$ref": "#/definitions/entities.ProductLink"
                 },
@@ -715,6 +1937,17 @@ const docTemplate = `{
                 }
             }
         },
+        "entities.ProductDelivery": {
+            "type": "object",
+            "properties": {
+                "date": {
+                    "type": "string"
+                },
+                "hours": {
+                    "type": "integer"
+                }
+            }
+        },
         "entities.ProductLink": {
             "type": "object",
             "properties": {
@@ -732,6 +1965,14 @@ const docTemplate = `{
                 "currency": {
                     "$ref": "#/definitions/entities.Currency"
                 },
+                "loading": {
+                    "description": "Loading is set only if the market's page was loaded through the browser.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/entities.PageLoading"
+                        }
+                    ]
+                },
                 "main_products_sample": {
                     "type": "string"
                 },
@@ -743,6 +1984,13 @@ const docTemplate = `{
                     "items": {
                         "$ref": "#/definitions/entities.Product"
                     }
+                },
+                "rejected": {
+                    "description": "Rejected is the amount of the market's products that didn't pass the validation by the reasons.",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer"
+                    }
                 }
             }
         },
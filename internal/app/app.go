@@ -1,6 +1,7 @@
 package app
 
 import (
+	"context"
 	"fmt"
 	"log/slog"
 	"os"
@@ -20,9 +21,13 @@ import (
 	"github.com/MaKcm14/price-service/pkg/entities"
 )
 
+// jobsDrainTimeout is the max time of waiting the running async jobs when the app is stopping.
+const jobsDrainTimeout = time.Second * 30
+
 // Service unions every parts of the application.
 type Service struct {
 	appContr    chttp.Controller
+	appFilter   filter.ProductsFilter
 	chrome      services.Driver
 	producer    services.AsyncWriter
 	logger      *slog.Logger
@@ -44,14 +49,27 @@ func NewService() Service {
 
 	log.Info("main application's configuring begun")
 
-	appSet, err := config.NewSettings(log, config.Socket, config.ByPassSocket, config.Brokers)
+	appSet, err := config.NewSettings(log, config.Socket, config.MegaMarketMode, config.ByPassSocket, config.Brokers,
+		config.MarketsDeadlines, config.PagesCap, config.JobsDeadline, config.JobsPool, config.WildberriesImages, config.Chrome, config.ChromeBrowser)
 
 	if err != nil {
 		mainLogFile.Close()
 		panic(err)
 	}
 
-	chrome := api.NewChromePull()
+	chrome := api.NewChromePull(
+		api.WithPullLogger(log),
+		api.WithBrowsers(appSet.Chrome.Browsers, appSet.Chrome.Tabs),
+		api.WithAcquireTimeout(appSet.Chrome.AcquireTimeout),
+		api.WithHoldTimeout(appSet.Chrome.HoldTimeout),
+		api.WithFailFast(appSet.Chrome.FailFast),
+		api.WithHeadless(appSet.Chrome.Headless),
+		api.WithRemoteURL(appSet.Chrome.RemoteURL),
+		api.WithUserDataDir(appSet.Chrome.UserDataDir),
+		api.WithWindowSize(appSet.Chrome.WindowWidth, appSet.Chrome.WindowHeight),
+		api.WithProxy(appSet.Chrome.Proxy),
+		api.WithFlags(appSet.Chrome.Flags),
+	)
 
 	producer, err := kafka.NewProducer(log, appSet.Brokers)
 
@@ -60,14 +78,23 @@ func NewService() Service {
 		panic(err)
 	}
 
+	appFilter := filter.New(
+		log,
+		map[entities.Market]services.ApiInteractor{
+			entities.Wildberries: wildb.NewWildberriesAPI(chrome, log, 1,
+				wildb.WithImagesSource(wildb.ImagesSource(appSet.WildberriesImages))),
+			entities.MegaMarket: mmega.NewMegaMarketAPI(context.Background(), log, appSet.ByPassSocket,
+				mmega.WithMode(mmega.Mode(appSet.MegaMarketMode))),
+		}, producer,
+		filter.WithMarketsDeadlines(appSet.MarketsDeadlines),
+		filter.WithPagesCap(appSet.PagesCap),
+		filter.WithJobsDeadline(appSet.JobsDeadline),
+		filter.WithJobsWorkers(appSet.JobsWorkers),
+		filter.WithJobsQueueSize(appSet.JobsQueueSize))
+
 	return Service{
-		appContr: chttp.NewController(echo.New(), log,
-			filter.New(
-				log,
-				map[entities.Market]services.ApiInteractor{
-					entities.Wildberries: wildb.NewWildberriesAPI(chrome.NewContext(), log, 1),
-					entities.MegaMarket:  mmega.NewMegaMarketAPI(chrome.NewContext(), log, appSet.ByPassSocket),
-				}, producer)),
+		appContr:    chttp.NewController(echo.New(), log, appFilter, chttp.WithDriver(chrome)),
+		appFilter:   appFilter,
 		logger:      log,
 		mainLogFile: mainLogFile,
 		chrome:      chrome,
@@ -85,4 +112,13 @@ func (s Service) Run() {
 
 	s.logger.Info("the app was STARTED")
 	s.appContr.Run(s.appSet.Socket)
+
+	ctx, cancel := context.WithTimeout(context.Background(), jobsDrainTimeout)
+	defer cancel()
+
+	if err := s.appFilter.Shutdown(ctx); err != nil {
+		s.logger.Warn(fmt.Sprintf("the async jobs weren't drained: %v", err))
+	} else {
+		s.logger.Info("the async jobs were drained")
+	}
 }
@@ -4,18 +4,59 @@ import (
 	"fmt"
 	"log/slog"
 	"os"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
+
+	"github.com/MaKcm14/price-service/pkg/entities"
 )
 
 type SettingOpt func(*Settings, *slog.Logger) error
 
+// ChromeSettings sets the configurations of the pull of the browser's tabs.
+type ChromeSettings struct {
+	Browsers       int
+	Tabs           int
+	AcquireTimeout time.Duration
+	HoldTimeout    time.Duration
+	FailFast       bool
+
+	Headless     bool
+	RemoteURL    string
+	UserDataDir  string
+	WindowWidth  int
+	WindowHeight int
+	Proxy        string
+	Flags        []string
+}
+
 // Settings sets the application's configurations.
 type Settings struct {
 	Socket       string
 	ByPassSocket string
-	Brokers      []string
+
+	// MegaMarketMode is the way of the interaction with the megamarket: "native" or "by-pass".
+	MegaMarketMode string
+
+	Brokers          []string
+	MarketsDeadlines map[entities.Market]time.Duration
+	PagesCap         int
+
+	// JobsDeadline is the max time of processing every async job.
+	JobsDeadline time.Duration
+
+	// JobsWorkers is the amount of the async jobs that are processed at the same time.
+	JobsWorkers int
+
+	// JobsQueueSize is the max amount of the queued async jobs.
+	JobsQueueSize int
+
+	// WildberriesImages is the source of the wildberries' image links: "id" or "browser".
+	WildberriesImages string
+
+	Chrome ChromeSettings
 }
 
 // configEnv gets ENV var. It returns the error if var is unset or unexisting.
@@ -43,8 +84,33 @@ func Socket(appSet *Settings, log *slog.Logger) error {
 	return nil
 }
 
+// MegaMarketMode configs the MEGAMARKET_MODE ENV defines the way of the interaction with the megamarket:
+// "native" (the direct requests to the market's catalog API) or "by-pass" (the requests through the by-pass-service).
+func MegaMarketMode(appSet *Settings, log *slog.Logger) error {
+	env, err := configEnv("MEGAMARKET_MODE", log)
+
+	if err != nil {
+		return err
+	}
+
+	if env != "native" && env != "by-pass" {
+		envErr := fmt.Errorf("error while parsing the .env file: check the MEGAMARKET_MODE var is \"native\" or \"by-pass\"")
+		log.Error(envErr.Error())
+		return envErr
+	}
+	appSet.MegaMarketMode = env
+
+	return nil
+}
+
 // ByPassSocket configs the ByPassSocket ENV defines the by-pass-service's socket.
+// It's required only in the "by-pass" megamarket's mode so it must be set after the MegaMarketMode.
 func ByPassSocket(appSet *Settings, log *slog.Logger) error {
+	if appSet.MegaMarketMode == "native" {
+		appSet.ByPassSocket = os.Getenv("BY_PASS_SOCKET")
+		return nil
+	}
+
 	socket, err := configEnv("BY_PASS_SOCKET", log)
 
 	if err != nil {
@@ -67,6 +133,218 @@ func Brokers(appSet *Settings, log *slog.Logger) error {
 	return nil
 }
 
+// MarketsDeadlines configs the WILDBERRIES_DEADLINE and MEGAMARKET_DEADLINE ENVs define the max time
+// of waiting the response from every market.
+func MarketsDeadlines(appSet *Settings, log *slog.Logger) error {
+	envs := map[entities.Market]string{
+		entities.Wildberries: "WILDBERRIES_DEADLINE",
+		entities.MegaMarket:  "MEGAMARKET_DEADLINE",
+	}
+	appSet.MarketsDeadlines = make(map[entities.Market]time.Duration, len(envs))
+
+	for market, key := range envs {
+		env, err := configEnv(key, log)
+
+		if err != nil {
+			return err
+		}
+
+		deadline, err := time.ParseDuration(env)
+
+		if err != nil || deadline <= 0 {
+			envErr := fmt.Errorf("error while parsing the .env file: check the %s var is the positive duration", key)
+			log.Error(envErr.Error())
+			return envErr
+		}
+		appSet.MarketsDeadlines[market] = deadline
+	}
+
+	return nil
+}
+
+// PagesCap configs the PAGES_CAP ENV defines the max amount of the pages that are got from every market
+// while the limit of the products is collecting.
+func PagesCap(appSet *Settings, log *slog.Logger) error {
+	env, err := configEnv("PAGES_CAP", log)
+
+	if err != nil {
+		return err
+	}
+
+	pagesCap, err := strconv.Atoi(env)
+
+	if err != nil || pagesCap <= 0 {
+		envErr := fmt.Errorf("error while parsing the .env file: check the PAGES_CAP var is the natural number")
+		log.Error(envErr.Error())
+		return envErr
+	}
+	appSet.PagesCap = pagesCap
+
+	return nil
+}
+
+// JobsDeadline configs the JOBS_DEADLINE ENV defines the max time of processing every async job:
+// the job that hasn't finished in this time is failed.
+func JobsDeadline(appSet *Settings, log *slog.Logger) error {
+	env, err := configEnv("JOBS_DEADLINE", log)
+
+	if err != nil {
+		return err
+	}
+
+	deadline, err := time.ParseDuration(env)
+
+	if err != nil || deadline <= 0 {
+		envErr := fmt.Errorf("error while parsing the .env file: check the JOBS_DEADLINE var is the positive duration")
+		log.Error(envErr.Error())
+		return envErr
+	}
+	appSet.JobsDeadline = deadline
+
+	return nil
+}
+
+// JobsPool configs the ASYNC_WORKERS and the ASYNC_QUEUE_SIZE ENVs define the amount of the async jobs
+// that are processed at the same time and the max amount of the queued async jobs.
+func JobsPool(appSet *Settings, log *slog.Logger) error {
+	envs := map[string]*int{
+		"ASYNC_WORKERS":    &appSet.JobsWorkers,
+		"ASYNC_QUEUE_SIZE": &appSet.JobsQueueSize,
+	}
+
+	for key, value := range envs {
+		env, err := configEnv(key, log)
+
+		if err != nil {
+			return err
+		}
+
+		num, err := strconv.Atoi(env)
+
+		if err != nil || num <= 0 {
+			envErr := fmt.Errorf("error while parsing the .env file: check the %s var is the natural number", key)
+			log.Error(envErr.Error())
+			return envErr
+		}
+		*value = num
+	}
+
+	return nil
+}
+
+// WildberriesImages configs the WILDBERRIES_IMAGES ENV defines the source of the wildberries' image links:
+// "id" (the links are derived from the products' IDs) or "browser" (the links are parsed from the page).
+func WildberriesImages(appSet *Settings, log *slog.Logger) error {
+	env, err := configEnv("WILDBERRIES_IMAGES", log)
+
+	if err != nil {
+		return err
+	}
+
+	if env != "id" && env != "browser" {
+		envErr := fmt.Errorf("error while parsing the .env file: check the WILDBERRIES_IMAGES var is \"id\" or \"browser\"")
+		log.Error(envErr.Error())
+		return envErr
+	}
+	appSet.WildberriesImages = env
+
+	return nil
+}
+
+// Chrome configs the CHROME_BROWSERS, CHROME_TABS, CHROME_ACQUIRE_TIMEOUT, CHROME_HOLD_TIMEOUT and
+// CHROME_FAIL_FAST ENVs define the pull of the browser's tabs.
+func Chrome(appSet *Settings, log *slog.Logger) error {
+	counts := map[string]*int{
+		"CHROME_BROWSERS": &appSet.Chrome.Browsers,
+		"CHROME_TABS":     &appSet.Chrome.Tabs,
+	}
+
+	for key, count := range counts {
+		env, err := configEnv(key, log)
+
+		if err != nil {
+			return err
+		}
+
+		if *count, err = strconv.Atoi(env); err != nil || *count <= 0 {
+			envErr := fmt.Errorf("error while parsing the .env file: check the %s var is the natural number", key)
+			log.Error(envErr.Error())
+			return envErr
+		}
+	}
+
+	timeouts := map[string]*time.Duration{
+		"CHROME_ACQUIRE_TIMEOUT": &appSet.Chrome.AcquireTimeout,
+		"CHROME_HOLD_TIMEOUT":    &appSet.Chrome.HoldTimeout,
+	}
+
+	for key, timeout := range timeouts {
+		env, err := configEnv(key, log)
+
+		if err != nil {
+			return err
+		}
+
+		if *timeout, err = time.ParseDuration(env); err != nil || *timeout <= 0 {
+			envErr := fmt.Errorf("error while parsing the .env file: check the %s var is the positive duration", key)
+			log.Error(envErr.Error())
+			return envErr
+		}
+	}
+
+	failFast, err := configEnv("CHROME_FAIL_FAST", log)
+
+	if err != nil {
+		return err
+	}
+
+	if failFast != "1" && failFast != "0" {
+		envErr := fmt.Errorf("error while parsing the .env file: check the CHROME_FAIL_FAST var is \"1\" or \"0\"")
+		log.Error(envErr.Error())
+		return envErr
+	}
+	appSet.Chrome.FailFast = failFast == "1"
+
+	return nil
+}
+
+// ChromeBrowser configs the CHROME_HEADLESS ENV defines the browser's running without the display and
+// the optional CHROME_REMOTE_URL, CHROME_USER_DATA_DIR, CHROME_WINDOW_SIZE, CHROME_PROXY and CHROME_FLAGS ENVs
+// define the remote browser's DevTools endpoint and the options of the started browsers.
+func ChromeBrowser(appSet *Settings, log *slog.Logger) error {
+	headless, err := configEnv("CHROME_HEADLESS", log)
+
+	if err != nil {
+		return err
+	}
+
+	if headless != "1" && headless != "0" {
+		envErr := fmt.Errorf("error while parsing the .env file: check the CHROME_HEADLESS var is \"1\" or \"0\"")
+		log.Error(envErr.Error())
+		return envErr
+	}
+	appSet.Chrome.Headless = headless == "1"
+
+	appSet.Chrome.RemoteURL = os.Getenv("CHROME_REMOTE_URL")
+	appSet.Chrome.UserDataDir = os.Getenv("CHROME_USER_DATA_DIR")
+	appSet.Chrome.Proxy = os.Getenv("CHROME_PROXY")
+	appSet.Chrome.Flags = strings.Fields(os.Getenv("CHROME_FLAGS"))
+
+	if windowSize := os.Getenv("CHROME_WINDOW_SIZE"); len(windowSize) != 0 {
+		width, height, _ := strings.Cut(windowSize, "x")
+		appSet.Chrome.WindowWidth, _ = strconv.Atoi(width)
+		appSet.Chrome.WindowHeight, _ = strconv.Atoi(height)
+
+		if appSet.Chrome.WindowWidth <= 0 || appSet.Chrome.WindowHeight <= 0 {
+			envErr := fmt.Errorf("error while parsing the .env file: check the CHROME_WINDOW_SIZE var is in the \"WIDTHxHEIGHT\" format")
+			log.Error(envErr.Error())
+			return envErr
+		}
+	}
+
+	return nil
+}
+
 func NewSettings(log *slog.Logger, opts ...SettingOpt) (Settings, error) {
 	appSet := Settings{}
 	err := godotenv.Load("../../.env")
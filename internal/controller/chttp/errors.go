@@ -1,16 +1,41 @@
 package chttp
 
-import "errors"
+import (
+	"errors"
+
+	"github.com/MaKcm14/price-service/internal/entities/dto"
+	"github.com/MaKcm14/price-service/pkg/entities"
+)
 
 var (
-	ErrRequest        = errors.New("the server couldn't handle the current request")
-	ErrRequestInfo    = errors.New("the wrong request data was got")
-	ErrRequestPath    = errors.New("try to request to unknown resource")
-	ErrServerHandling = errors.New("the server couldn't handle the response")
-	ErrExternalServer = errors.New("the external server couldn't handle the response")
+	ErrRequest         = errors.New("the server couldn't handle the current request")
+	ErrRequestInfo     = errors.New("the wrong request data was got")
+	ErrRequestPath     = errors.New("try to request to unknown resource")
+	ErrServerHandling  = errors.New("the server couldn't handle the response")
+	ErrExternalServer  = errors.New("the external server couldn't handle the response")
+	ErrJobNotFound     = errors.New("the job wasn't found or its result has expired")
+	ErrJobTimeout      = errors.New("the job hasn't finished in the set time")
+	ErrJobCancelled    = errors.New("the job was cancelled")
+	ErrJobFinished     = errors.New("the job is already finished")
+	ErrJobsQueueFull   = errors.New("too many async requests are queued: repeat the request later")
+	ErrServiceStopping = errors.New("the server is stopping and doesn't accept the async requests")
 )
 
 // ResponseErr is the wrapper for the errors' response.
 type ResponseErr struct {
 	Error string `json:"error"`
 }
+
+// ProductsErr is the wrapper for the products' errors' response: it contains the statuses of the markets
+// that define why the products weren't got.
+type ProductsErr struct {
+	Error    string                           `json:"error"`
+	Statuses map[string]entities.MarketStatus `json:"statuses"`
+}
+
+func NewProductsErr(err error, response dto.FilterResponse) ProductsErr {
+	return ProductsErr{
+		Error:    err.Error(),
+		Statuses: NewProductResponse(response).Statuses,
+	}
+}
@@ -12,7 +12,10 @@ import (
 )
 
 const (
-	nameTestMarket1 = "TestMarket1"
+	nameTestMarket1   = "TestMarket1"
+	testJobID         = "test-job-id"
+	testRunningJobID  = "test-running-job-id"
+	testFinishedJobID = "test-finished-job-id"
 )
 
 type productsFilterMock struct {
@@ -20,6 +23,9 @@ type productsFilterMock struct {
 
 	negativeInteraction bool
 	serviceError        bool
+
+	// asyncError is returned by the async filters if it's set.
+	asyncError error
 }
 
 func newProductsFilterMock(negativeInteraction bool, serviceError bool) *productsFilterMock {
@@ -29,57 +35,108 @@ func newProductsFilterMock(negativeInteraction bool, serviceError bool) *product
 	}
 }
 
-func (m *productsFilterMock) getPositiveCaseSample() []entities.ProductSample {
-	return []entities.ProductSample{
-		{
-			Products: []entities.Product{{}},
-			Market:   nameTestMarket1,
+func (m *productsFilterMock) getPositiveCaseSample() dto.FilterResponse {
+	return dto.FilterResponse{
+		Samples: []entities.ProductSample{
+			{
+				Products: []entities.Product{{}},
+				Market:   nameTestMarket1,
+			},
+		},
+		Statuses: []entities.MarketStatus{
+			{
+				Market: nameTestMarket1,
+				Status: entities.MarketStatusOK,
+			},
 		},
 	}
 }
 
-func (m *productsFilterMock) FilterByMarkets(ctx echo.Context, request dto.ProductRequest) ([]entities.ProductSample, error) {
+func (m *productsFilterMock) FilterByMarkets(ctx echo.Context, request dto.ProductRequest) (dto.FilterResponse, error) {
 	if m.serviceError {
-		return nil, fmt.Errorf("error of the filter: internal server error")
+		return dto.FilterResponse{}, fmt.Errorf("error of the filter: internal server error")
 	} else if m.negativeInteraction {
-		return nil, services.ErrGettingProducts
+		return dto.FilterResponse{}, services.ErrGettingProducts
 	}
 	return m.getPositiveCaseSample(), nil
 }
 
-func (m *productsFilterMock) FilterByPriceRange(ctx echo.Context, request dto.ProductRequest) ([]entities.ProductSample, error) {
+func (m *productsFilterMock) FilterByPriceRange(ctx echo.Context, request dto.ProductRequest) (dto.FilterResponse, error) {
 	m.Called(ctx, request)
 
 	if m.serviceError {
-		return nil, fmt.Errorf("error of the filter: internal server error")
+		return dto.FilterResponse{}, fmt.Errorf("error of the filter: internal server error")
 	} else if m.negativeInteraction {
-		return nil, services.ErrGettingProducts
+		return dto.FilterResponse{}, services.ErrGettingProducts
 	}
 
 	return m.getPositiveCaseSample(), nil
 }
 
-func (m *productsFilterMock) FilterByBestPrice(ctx echo.Context, request dto.ProductRequest) ([]entities.ProductSample, error) {
+func (m *productsFilterMock) FilterByBestPrice(ctx echo.Context, request dto.ProductRequest) (dto.FilterResponse, error) {
 	if m.serviceError {
-		return nil, fmt.Errorf("error of the filter: internal server error")
+		return dto.FilterResponse{}, fmt.Errorf("error of the filter: internal server error")
 	} else if m.negativeInteraction {
-		return nil, services.ErrGettingProducts
+		return dto.FilterResponse{}, services.ErrGettingProducts
 	}
 	return m.getPositiveCaseSample(), nil
 }
 
-func (m *productsFilterMock) FilterByExactPrice(ctx echo.Context, request dto.ProductRequest) ([]entities.ProductSample, error) {
+func (m *productsFilterMock) FilterByExactPrice(ctx echo.Context, request dto.ProductRequest) (dto.FilterResponse, error) {
 	m.Called(ctx, request)
 
 	if m.serviceError {
-		return nil, fmt.Errorf("error of the filter: internal server error")
+		return dto.FilterResponse{}, fmt.Errorf("error of the filter: internal server error")
 	} else if m.negativeInteraction {
-		return nil, services.ErrGettingProducts
+		return dto.FilterResponse{}, services.ErrGettingProducts
 	}
 
 	return m.getPositiveCaseSample(), nil
 }
 
-func (m *productsFilterMock) FilterByBestPriceAsync(ctx echo.Context, request dto.ProductRequest) {
-	m.Called(ctx, request)
+func (m *productsFilterMock) filterAsync(request dto.ProductRequest) (dto.Job, error) {
+	if m.asyncError != nil {
+		return dto.Job{}, m.asyncError
+	} else if m.serviceError {
+		return dto.Job{}, services.ErrJobCreation
+	}
+	return dto.Job{ID: testJobID, State: dto.JobQueued}, nil
+}
+
+func (m *productsFilterMock) FilterByMarketsAsync(request dto.ProductRequest) (dto.Job, error) {
+	m.Called(request)
+	return m.filterAsync(request)
+}
+
+func (m *productsFilterMock) FilterByPriceRangeAsync(request dto.ProductRequest) (dto.Job, error) {
+	m.Called(request)
+	return m.filterAsync(request)
+}
+
+func (m *productsFilterMock) FilterByBestPriceAsync(request dto.ProductRequest) (dto.Job, error) {
+	m.Called(request)
+	return m.filterAsync(request)
+}
+
+func (m *productsFilterMock) FilterByExactPriceAsync(request dto.ProductRequest) (dto.Job, error) {
+	m.Called(request)
+	return m.filterAsync(request)
+}
+
+func (m *productsFilterMock) CancelJob(id string) (dto.Job, error) {
+	if id == testRunningJobID {
+		return dto.Job{ID: testRunningJobID, State: dto.JobRunning}, nil
+	} else if id == testFinishedJobID {
+		return dto.Job{ID: testFinishedJobID, State: dto.JobSucceeded}, services.ErrJobFinished
+	} else if id != testJobID {
+		return dto.Job{}, services.ErrJobNotFound
+	}
+	return dto.Job{ID: testJobID, State: dto.JobCancelled, Err: services.ErrJobCancelled}, nil
+}
+
+func (m *productsFilterMock) GetJob(id string) (dto.Job, error) {
+	if id != testJobID {
+		return dto.Job{}, services.ErrJobNotFound
+	}
+	return dto.Job{ID: testJobID, State: dto.JobSucceeded, Result: &dto.FilterResponse{}}, nil
 }
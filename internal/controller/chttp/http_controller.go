@@ -4,12 +4,12 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"expvar"
 	"fmt"
 	"log/slog"
 	"net/http"
 	"os"
 	"os/signal"
-	"strconv"
 	"syscall"
 	"time"
 
@@ -25,20 +25,44 @@ import (
 	_ "github.com/MaKcm14/price-service/docs"
 )
 
+// asyncRetryAfter is the time in seconds after which the rejected async request can be repeated.
+const asyncRetryAfter = 10
+
+// exposedMetrics are the names of the service's metrics (expvar) that are exposed: the rest process's
+// variables (the command line, the memory's stats) aren't public.
+var exposedMetrics = []string{"schema_drift", "async_jobs"}
+
+// Opt defines the optional settings of the Controller.
+type Opt func(*Controller)
+
+// WithDriver sets the browser's driver which stats are exposed.
+func WithDriver(driver services.Driver) Opt {
+	return func(c *Controller) {
+		c.driver = driver
+	}
+}
+
 // Controller handles the clients' requests.
 type Controller struct {
 	contr  *echo.Echo
 	logger *slog.Logger
 	filter filter.Filter
 	valid  validator
+	driver services.Driver
 }
 
-func NewController(contr *echo.Echo, logger *slog.Logger, filter filter.Filter) Controller {
-	return Controller{
+func NewController(contr *echo.Echo, logger *slog.Logger, filter filter.Filter, opts ...Opt) Controller {
+	controller := Controller{
 		contr:  contr,
 		logger: logger,
 		filter: filter,
 	}
+
+	for _, opt := range opts {
+		opt(&controller)
+	}
+
+	return controller
 }
 
 // Run configures and starts the http-server.
@@ -85,10 +109,20 @@ func (c *Controller) configPaths() {
 	c.contr.GET("/products/filter/price/exact-price", c.handleExactPriceRequest)
 	c.contr.GET("/products/filter/markets", c.handleMarketsRequest)
 
+	c.contr.POST("/products/filter/price/price-range/async", c.handlePriceRangeAsyncRequest)
 	c.contr.POST("/products/filter/price/best-price/async", c.handleBestPriceAsyncRequest)
+	c.contr.POST("/products/filter/price/exact-price/async", c.handleExactPriceAsyncRequest)
+	c.contr.POST("/products/filter/markets/async", c.handleMarketsAsyncRequest)
+	c.contr.GET("/jobs/:id", c.handleJobRequest)
+	c.contr.DELETE("/jobs/:id", c.handleJobCancelRequest)
 
 	c.contr.GET("/swagger/*", echoSwagger.WrapHandler)
 	c.contr.GET("/api/markets", c.handleMarkets)
+	c.contr.GET("/api/stats/metrics", c.handleMetrics)
+
+	if c.driver != nil {
+		c.contr.GET("/api/stats/driver", c.handleDriverStats)
+	}
 }
 
 // configMW configurates the controller's middleware.
@@ -122,6 +156,35 @@ func (c *Controller) setBasicHeaders(ctx echo.Context, buf []byte) {
 	ctx.Response().Header().Add("Content-Length", fmt.Sprintf("%d", len(buf)+1))
 }
 
+// sendProductsResponse sends the filter's response: if every market has failed the response contains
+// the markets' statuses with the error. The response isn't cached if some market has failed.
+func (c *Controller) sendProductsResponse(ctx echo.Context, filterType string, products dto.FilterResponse, err error) error {
+	if err != nil {
+		c.logger.Warn(fmt.Sprintf("error of the %v: %v", filterType, err))
+
+		if errors.Is(err, services.ErrGettingProducts) {
+			return ctx.JSON(http.StatusBadGateway, NewProductsErr(ErrExternalServer, products))
+		}
+
+		return ctx.JSON(http.StatusInternalServerError, ResponseErr{ErrServerHandling.Error()})
+	}
+
+	response := NewProductResponse(products)
+	buf, _ := json.Marshal(response)
+
+	c.setBasicHeaders(ctx, buf)
+
+	ctx.Response().Header().Add("Content-Type", "application/json; charset=utf-8")
+
+	if isDegraded(products) {
+		ctx.Response().Header().Add("Cache-Control", "no-store")
+	} else {
+		ctx.Response().Header().Add("Cache-Control", "public, max-age=43200")
+	}
+
+	return ctx.JSON(http.StatusOK, response)
+}
+
 // handlePriceRangeRequest defines the logic of the handling the filter-by-price-down-up requests.
 //
 //	@summary		price range filtering
@@ -134,15 +197,19 @@ func (c *Controller) setBasicHeaders(ctx echo.Context, buf []byte) {
 //	@param			price_up	query		integer		true	"the price range's upper bound: more than price_down"	minimum(1)
 //	@param			markets		query		[]string	true	"the list of the markets using for search"				Enums(wildberries, megamarket)				collectionFormat(ssv)	minLength(1)	example(megamarket+wildberries)
 //	@param			sample		query		integer		false	"the num of products' sample"							minimum(1)									default(1)
-//	@param			sort		query		string		false	"the type of products' sample sorting"					Enums(popular, pricedown, priceup, newly)	default(popular)
+//	@param			sort		query		string		false	"the type of products' sample sorting"					Enums(popular, pricedown, priceup, newly, rate)	default(popular)
 //	@param			no-image	query		integer		false	"the flag that defines 'Should image links be parsed?'"	Enums(0, 1)									default(1)
-//	@param			amount		query		string		false	"the amount of the products in response's sample"		Enums(min, max)								default(min)
+//	@param			availability	query		string		false	"the stock status of the sample's products"	Enums(in_stock, all, preorder)	default(in_stock)
+//	@param			merge		query		integer		false	"the flag that defines 'Should the samples be merged in one list?': it's sorted by the price for pricedown and priceup, the rest sorts interleave the products by their positions in the markets' samples"	Enums(0, 1)									default(0)
+//	@param			limit		query		integer		false	"the amount of the products that are collected from every market through the consecutive pages"	minimum(1)	maximum(300)
+//	@param			cursor		query		string		false	"the cursor of the previous response that continues it"
+//	@param			amount		query		integer		false	"the amount of the products in response's sample (min and max are the aliases of 15 and 100)"	minimum(1)	maximum(100)	default(15)
 //
 //
 //	@success		200			{object}	chttp.ProductResponse
 //	@failure		400			{object}	chttp.ResponseErr
 //	@failure		500			{object}	chttp.ResponseErr
-//	@failure		502			{object}	chttp.ResponseErr
+//	@failure		502			{object}	chttp.ProductsErr
 //	@router			/products/filter/price/price-range [get]
 func (c *Controller) handlePriceRangeRequest(ctx echo.Context) error {
 	const filterType = "price-range-filter"
@@ -154,67 +221,47 @@ func (c *Controller) handlePriceRangeRequest(ctx echo.Context) error {
 		c.valid.validSample,
 		c.valid.validSort,
 		c.valid.validNoImage,
+		c.valid.validAvailability,
+		c.valid.validMerge,
+		c.valid.validLimit,
+		c.valid.validPriceRange,
+		c.valid.validCursor,
 	)
 
 	if err != nil {
 		c.logger.Warn(fmt.Sprintf("error of the %v: %v", filterType, err))
 		return ctx.JSON(http.StatusBadRequest, ResponseErr{ErrRequestInfo.Error()})
 	}
-	priceDown, _ := strconv.Atoi(ctx.QueryParam("price_down"))
-	priceUp, _ := strconv.Atoi(ctx.QueryParam("price_up"))
-
-	if priceDown < 0 || priceUp <= 0 || priceUp < priceDown {
-		c.logger.Warn(fmt.Sprintf("error of the %v: %v", filterType, ErrRequestInfo))
-		return ctx.JSON(http.StatusBadRequest, ResponseErr{ErrRequestInfo.Error()})
-	}
-
-	requestInfo.PriceRange = dto.PriceRangeRequest{
-		PriceDown: priceDown,
-		PriceUp:   priceUp,
-	}
 
 	products, err := c.filter.FilterByPriceRange(ctx, requestInfo)
 
-	if err != nil {
-		c.logger.Warn(fmt.Sprintf("error of the %v: %v", filterType, err))
-
-		if errors.Is(err, services.ErrGettingProducts) {
-			return ctx.JSON(http.StatusBadGateway, ResponseErr{ErrExternalServer.Error()})
-		}
-
-		return ctx.JSON(http.StatusInternalServerError, ResponseErr{ErrServerHandling.Error()})
-	}
-
-	response := NewProductResponse(products)
-	buf, _ := json.Marshal(response)
-
-	c.setBasicHeaders(ctx, buf)
-
-	ctx.Response().Header().Add("Content-Type", "application/json; charset=utf-8")
-	ctx.Response().Header().Add("Cache-Control", "public, max-age=43200")
-
-	return ctx.JSON(http.StatusOK, response)
+	return c.sendProductsResponse(ctx, filterType, products, err)
 }
 
 // handleBestPriceRequest defines the logic of the handling the filter-by-minimal-price requests.
 //
 //	@summary		best price filtering
-//	@description	this endpoint provides filtering products from marketplaces with the best and minimum price
+//	@description	this endpoint provides the top cheapest products across all the requested marketplaces with the comparison of the markets' minimum prices
 //	@tags			Price-Filters
 //	@produce		json
 //
 //	@param			query		query		[]string	true	"the exact query string"								collectionFormat(ssv)						minLength(1)			example(iphone+11)
 //	@param			markets		query		[]string	true	"the list of the markets using for search"				Enums(wildberries, megamarket)				collectionFormat(ssv)	minLength(1)	example(megamarket+wildberries)
 //	@param			sample		query		integer		false	"the num of products' sample"							minimum(1)									default(1)
-//	@param			sort		query		string		false	"the type of products' sample sorting"					Enums(popular, pricedown, priceup, newly)	default(popular)
+//	@param			sort		query		string		false	"the type of products' sample sorting"					Enums(popular, pricedown, priceup, newly, rate)	default(popular)
 //	@param			no-image	query		integer		false	"the flag that defines 'Should image links be parsed?'"	Enums(0, 1)									default(1)
-//	@param			amount		query		string		false	"the amount of the products in response's sample"		Enums(min, max)								default(min)
+//	@param			availability	query		string		false	"the stock status of the sample's products"	Enums(in_stock, all, preorder)	default(in_stock)
+//	@param			merge		query		integer		false	"the flag that defines 'Should the samples be merged in one list?': it's sorted by the price for pricedown and priceup, the rest sorts interleave the products by their positions in the markets' samples"	Enums(0, 1)									default(0)
+//	@param			limit		query		integer		false	"the amount of the products that are collected from every market through the consecutive pages"	minimum(1)	maximum(300)
+//	@param			cursor		query		string		false	"the cursor of the previous response that continues it"
+//	@param			top			query		integer		false	"the amount of the cheapest products across the markets"	minimum(1)	maximum(100)	default(10)
+//	@param			amount		query		integer		false	"the amount of the products in response's sample (min and max are the aliases of 15 and 100)"	minimum(1)	maximum(100)	default(15)
 //
 //
 //	@success		200			{object}	chttp.ProductResponse
 //	@failure		400			{object}	chttp.ResponseErr
 //	@failure		500			{object}	chttp.ResponseErr
-//	@failure		502			{object}	chttp.ResponseErr
+//	@failure		502			{object}	chttp.ProductsErr
 //	@router			/products/filter/price/best-price [get]
 func (c *Controller) handleBestPriceRequest(ctx echo.Context) error {
 	const filterType = "best-price-filter"
@@ -225,6 +272,11 @@ func (c *Controller) handleBestPriceRequest(ctx echo.Context) error {
 		c.valid.validAmount,
 		c.valid.validSample,
 		c.valid.validNoImage,
+		c.valid.validAvailability,
+		c.valid.validMerge,
+		c.valid.validLimit,
+		c.valid.validTop,
+		c.valid.validCursor,
 	)
 
 	if err != nil {
@@ -234,47 +286,36 @@ func (c *Controller) handleBestPriceRequest(ctx echo.Context) error {
 
 	products, err := c.filter.FilterByBestPrice(ctx, requestInfo)
 
-	if err != nil {
-		c.logger.Warn(fmt.Sprintf("error of the %v: %v", filterType, err))
-
-		if errors.Is(err, services.ErrGettingProducts) {
-			return ctx.JSON(http.StatusBadGateway, ResponseErr{ErrExternalServer.Error()})
-		}
-
-		return ctx.JSON(http.StatusInternalServerError, ResponseErr{ErrServerHandling.Error()})
-	}
-
-	response := NewProductResponse(products)
-	buf, _ := json.Marshal(response)
-
-	c.setBasicHeaders(ctx, buf)
-
-	ctx.Response().Header().Add("Content-Type", "application/json; charset=utf-8")
-	ctx.Response().Header().Add("Cache-Control", "public, max-age=43200")
-
-	return ctx.JSON(http.StatusOK, response)
+	return c.sendProductsResponse(ctx, filterType, products, err)
 }
 
 // handleExactPriceRequest defines the logic of the handling the filter-by-set-price requests.
 //
 //	@summary		exact price filtering
-//	@description	this endpoint provides filtering products from marketplaces with price in range (exact-price, exact-price * 1.05 (+5%))
+//	@description	this endpoint provides filtering products from marketplaces with price in the tolerance window around the exact price (exact-price, exact-price * 1.05 (+5%) by default): the products are ranked by the distance to the exact price
 //	@tags			Price-Filters
 //	@produce		json
 //
 //	@param			query		query		[]string	true	"the exact query string"									collectionFormat(ssv)	minLength(1)	example(iphone+11)
 //	@param			price		query		integer		true	"the value of exact price"									minimum(1)
+//	@param			tolerance	query		number		false	"the tolerance of the exact price: 0 is the exact match"							minimum(0)	default(5)
+//	@param			tolerance_type	query	string		false	"the type of the tolerance: percentage or absolute"		Enums(percent, absolute)	default(percent)
+//	@param			direction	query		string		false	"the direction of the tolerance window"						Enums(both, up, down)	default(up)
 //	@param			markets		query		[]string	true	"the list of the markets using for search"					Enums(wildberries, megamarket)				collectionFormat(ssv)	minLength(1)	example(megamarket+wildberries)
 //	@param			sample		query		integer		false	"the num of products' sample"								minimum(1)									default(1)
-//	@param			sort		query		string		false	"the type of products' sample sorting"						Enums(popular, pricedown, priceup, newly)	default(popular)
+//	@param			sort		query		string		false	"the type of products' sample sorting"						Enums(popular, pricedown, priceup, newly, rate)	default(popular)
 //	@param			no-image	query		integer		false	"the flag that defines 'Should image links be parsed??'"	Enums(0, 1)									default(1)
-//	@param			amount		query		string		false	"the amount of the products in response's sample"			Enums(min, max)								default(min)
+//	@param			availability	query		string		false	"the stock status of the sample's products"	Enums(in_stock, all, preorder)	default(in_stock)
+//	@param			merge		query		integer		false	"the flag that defines 'Should the samples be merged in one list?': it's sorted by the price for pricedown and priceup, the rest sorts interleave the products by their positions in the markets' samples"	Enums(0, 1)									default(0)
+//	@param			limit		query		integer		false	"the amount of the products that are collected from every market through the consecutive pages"	minimum(1)	maximum(300)
+//	@param			cursor		query		string		false	"the cursor of the previous response that continues it"
+//	@param			amount		query		integer		false	"the amount of the products in response's sample (min and max are the aliases of 15 and 100)"	minimum(1)	maximum(100)	default(15)
 //
 //
 //	@success		200			{object}	chttp.ProductResponse
 //	@failure		400			{object}	chttp.ResponseErr
 //	@failure		500			{object}	chttp.ResponseErr
-//	@failure		502			{object}	chttp.ResponseErr
+//	@failure		502			{object}	chttp.ProductsErr
 //	@router			/products/filter/price/exact-price [get]
 func (c *Controller) handleExactPriceRequest(ctx echo.Context) error {
 	const filterType = "exact-price-filter"
@@ -286,6 +327,12 @@ func (c *Controller) handleExactPriceRequest(ctx echo.Context) error {
 		c.valid.validSample,
 		c.valid.validSort,
 		c.valid.validNoImage,
+		c.valid.validAvailability,
+		c.valid.validMerge,
+		c.valid.validLimit,
+		c.valid.validTolerance,
+		c.valid.validExactPrice,
+		c.valid.validCursor,
 	)
 
 	if err != nil {
@@ -293,35 +340,9 @@ func (c *Controller) handleExactPriceRequest(ctx echo.Context) error {
 		return ctx.JSON(http.StatusBadRequest, ResponseErr{ErrRequestInfo.Error()})
 	}
 
-	exactPrice, _ := strconv.Atoi(ctx.QueryParam("price"))
-
-	if exactPrice <= 0 {
-		c.logger.Warn(fmt.Sprintf("error of the %v: %v", filterType, ErrRequestInfo))
-		return ctx.JSON(http.StatusBadRequest, ResponseErr{ErrRequestInfo.Error()})
-	}
-
-	requestInfo.ExactPrice = exactPrice
-
 	products, err := c.filter.FilterByExactPrice(ctx, requestInfo)
 
-	if err != nil {
-		c.logger.Warn(fmt.Sprintf("error of the %v: %v", filterType, err))
-
-		if errors.Is(err, services.ErrGettingProducts) {
-			return ctx.JSON(http.StatusBadGateway, ResponseErr{ErrExternalServer.Error()})
-		}
-
-		return ctx.JSON(http.StatusInternalServerError, ResponseErr{ErrServerHandling.Error()})
-	}
-	response := NewProductResponse(products)
-	buf, _ := json.Marshal(response)
-
-	c.setBasicHeaders(ctx, buf)
-
-	ctx.Response().Header().Add("Content-Type", "application/json; charset=utf-8")
-	ctx.Response().Header().Add("Cache-Control", "public, max-age=43200")
-
-	return ctx.JSON(http.StatusOK, response)
+	return c.sendProductsResponse(ctx, filterType, products, err)
 }
 
 // handleMarketsRequest defines the logic of the handling the filter-by-markets requests.
@@ -334,15 +355,19 @@ func (c *Controller) handleExactPriceRequest(ctx echo.Context) error {
 //	@param			query		query		[]string	true	"the exact query string"								collectionFormat(ssv)						minLength(1)			example(iphone+11)
 //	@param			markets		query		[]string	true	"the list of the markets using for search"				Enums(wildberries, megamarket)				collectionFormat(ssv)	minLength(1)	example(megamarket+wildberries)
 //	@param			sample		query		integer		false	"the num of products' sample"							minimum(1)									default(1)
-//	@param			sort		query		string		false	"the type of products' sample sorting"					Enums(popular, pricedown, priceup, newly)	default(popular)
+//	@param			sort		query		string		false	"the type of products' sample sorting"					Enums(popular, pricedown, priceup, newly, rate)	default(popular)
 //	@param			no-image	query		integer		false	"the flag that defines 'Should image links be parsed?'"	Enums(0, 1)									default(1)
-//	@param			amount		query		string		false	"the amount of the products in response's sample"		Enums(min, max)								default(min)
+//	@param			availability	query		string		false	"the stock status of the sample's products"	Enums(in_stock, all, preorder)	default(in_stock)
+//	@param			merge		query		integer		false	"the flag that defines 'Should the samples be merged in one list?': it's sorted by the price for pricedown and priceup, the rest sorts interleave the products by their positions in the markets' samples"	Enums(0, 1)									default(0)
+//	@param			limit		query		integer		false	"the amount of the products that are collected from every market through the consecutive pages"	minimum(1)	maximum(300)
+//	@param			cursor		query		string		false	"the cursor of the previous response that continues it"
+//	@param			amount		query		integer		false	"the amount of the products in response's sample (min and max are the aliases of 15 and 100)"	minimum(1)	maximum(100)	default(15)
 //
 //
 //	@success		200			{object}	chttp.ProductResponse
 //	@failure		400			{object}	chttp.ResponseErr
 //	@failure		500			{object}	chttp.ResponseErr
-//	@failure		502			{object}	chttp.ResponseErr
+//	@failure		502			{object}	chttp.ProductsErr
 //	@router			/products/filter/markets [get]
 func (c *Controller) handleMarketsRequest(ctx echo.Context) error {
 	const filterType = "markets-filter"
@@ -354,6 +379,10 @@ func (c *Controller) handleMarketsRequest(ctx echo.Context) error {
 		c.valid.validSample,
 		c.valid.validSort,
 		c.valid.validNoImage,
+		c.valid.validAvailability,
+		c.valid.validMerge,
+		c.valid.validLimit,
+		c.valid.validCursor,
 	)
 
 	if err != nil {
@@ -363,25 +392,7 @@ func (c *Controller) handleMarketsRequest(ctx echo.Context) error {
 
 	products, err := c.filter.FilterByMarkets(ctx, requestInfo)
 
-	if err != nil {
-		c.logger.Warn(fmt.Sprintf("error of the %v: %v", filterType, ErrServerHandling))
-
-		if errors.Is(err, services.ErrGettingProducts) {
-			return ctx.JSON(http.StatusBadGateway, ResponseErr{ErrExternalServer.Error()})
-		}
-
-		return ctx.JSON(http.StatusInternalServerError, ResponseErr{ErrServerHandling.Error()})
-	}
-
-	response := NewProductResponse(products)
-	buf, _ := json.Marshal(response)
-
-	c.setBasicHeaders(ctx, buf)
-
-	ctx.Response().Header().Add("Content-Type", "application/json; charset=utf-8")
-	ctx.Response().Header().Add("Cache-Control", "public, max-age=43200")
-
-	return ctx.JSON(http.StatusOK, response)
+	return c.sendProductsResponse(ctx, filterType, products, err)
 }
 
 // handleMarkets defines the logic of handling the markets request:
@@ -398,24 +409,70 @@ func (c *Controller) handleMarkets(ctx echo.Context) error {
 	return ctx.JSON(http.StatusOK, entities.GetSupportedMarkets())
 }
 
+// handleDriverStats defines the logic of handling the driver's stats request:
+// it returns the current state of the pull of the browser's tabs.
+//
+//	@summary		driver's stats getting
+//	@description	this endpoint provides getting the current state of the pull of the browser's tabs
+//	@tags			Service-Info
+//	@produce		json
+//
+//	@success		200	{object}	entities.DriverStats
+//	@router			/api/stats/driver [get]
+func (c *Controller) handleDriverStats(ctx echo.Context) error {
+	return ctx.JSON(http.StatusOK, c.driver.Stats())
+}
+
+// handleMetrics defines the logic of handling the service's metrics request:
+// it returns only the exposed metrics.
+//
+//	@summary		service's metrics getting
+//	@description	this endpoint provides getting the service's metrics: the schema's drift of the markets and the async jobs' queue
+//	@tags			Service-Info
+//	@produce		json
+//
+//	@success		200	{object}	map[string]object
+//	@router			/api/stats/metrics [get]
+func (c *Controller) handleMetrics(ctx echo.Context) error {
+	metrics := make(map[string]json.RawMessage, len(exposedMetrics))
+
+	for _, name := range exposedMetrics {
+		if metric := expvar.Get(name); metric != nil {
+			metrics[name] = json.RawMessage(metric.String())
+		}
+	}
+
+	return ctx.JSON(http.StatusOK, metrics)
+}
+
 // handleBestPriceAsyncRequest defines the logic of handling the best-price request
 // with the async processing.
 //
 //	@summary		async best price filtering
-//	@description	this endpoint provides filtering products from marketplaces with the best and minimum price in async mode
+//	@description	this endpoint provides the top cheapest products across all the requested marketplaces with the comparison of the markets' minimum prices in async mode
 //	@tags			Price-Filters
 //	@produce		json
 //
 //	@param			query		query	[]string			true	"the exact query string"								collectionFormat(ssv)						minLength(1)			example(iphone+11)
 //	@param			markets		query	[]string			true	"the list of the markets using for search"				Enums(wildberries, megamarket)				collectionFormat(ssv)	minLength(1)	example(megamarket+wildberries)
 //	@param			sample		query	integer				false	"the num of products' sample"							minimum(1)									default(1)
-//	@param			sort		query	string				false	"the type of products' sample sorting"					Enums(popular, pricedown, priceup, newly)	default(popular)
+//	@param			sort		query	string				false	"the type of products' sample sorting"					Enums(popular, pricedown, priceup, newly, rate)	default(popular)
 //	@param			no-image	query	integer				false	"the flag that defines 'Should image links be parsed?'"	Enums(0, 1)									default(1)
-//	@param			amount		query	string				false	"the amount of the products in response's sample"		Enums(min, max)								default(min)
+//	@param			availability	query	string				false	"the stock status of the sample's products"	Enums(in_stock, all, preorder)	default(in_stock)
+//	@param			priority		query	string				false	"the priority of the async request's job in the queue"	Enums(high, normal, low)	default(normal)
+//	@param			merge		query	integer				false	"the flag that defines 'Should the samples be merged in one list?': it's sorted by the price for pricedown and priceup, the rest sorts interleave the products by their positions in the markets' samples"	Enums(0, 1)									default(0)
+//	@param			limit		query	integer				false	"the amount of the products that are collected from every market through the consecutive pages"	minimum(1)	maximum(300)
+//	@param			cursor		query	string				false	"the cursor of the previous response that continues it"
+//	@param			top			query	integer				false	"the amount of the cheapest products across the markets"	minimum(1)	maximum(100)	default(10)
+//	@param			amount		query	integer				false	"the amount of the products in response's sample (min and max are the aliases of 15 and 100)"	minimum(1)	maximum(100)	default(15)
 //	@param			request		body	chttp.extraHeaders	true	"the headers that need to be included into the async response"
 //
-//	@success		200
+//	@success		202	{object}	chttp.JobResponse
 //	@failure		400	{object}	chttp.ResponseErr
+//	@failure		429	{object}	chttp.ResponseErr
+//	@header			429	{integer}	Retry-After	"the amount of the seconds after that the request can be repeated"
+//	@failure		500	{object}	chttp.ResponseErr
+//	@failure		503	{object}	chttp.ResponseErr
 //	@router			/products/filter/price/best-price/async [post]
 func (c *Controller) handleBestPriceAsyncRequest(ctx echo.Context) error {
 	const filterType = "async-best-price-filter"
@@ -426,16 +483,275 @@ func (c *Controller) handleBestPriceAsyncRequest(ctx echo.Context) error {
 		c.valid.validAmount,
 		c.valid.validSample,
 		c.valid.validNoImage,
+		c.valid.validAvailability,
+		c.valid.validMerge,
+		c.valid.validLimit,
+		c.valid.validTop,
+		c.valid.validPriority,
 		c.valid.validExtraHeaders,
+		c.valid.validCursor,
+	)
+
+	if err != nil {
+		c.logger.Warn(fmt.Sprintf("error of the %v: %v", filterType, err))
+		return ctx.JSON(http.StatusBadRequest, ResponseErr{err.Error()})
+	}
+
+	return c.handleAsyncRequest(ctx, filterType, requestInfo, c.filter.FilterByBestPriceAsync)
+}
+
+// handlePriceRangeAsyncRequest defines the logic of handling the price-range request
+// with the async processing.
+//
+//	@summary		async price range filtering
+//	@description	this endpoint provides filtering products from marketplaces with specified price range in async mode
+//	@tags			Price-Filters
+//	@produce		json
+//
+//	@param			query		query	[]string			true	"the exact query string"								collectionFormat(ssv)						minLength(1)			example(iphone+11)
+//	@param			price_down	query	integer				true	"the price range's lower bound: less than price_up"		minimum(0)
+//	@param			price_up	query	integer				true	"the price range's upper bound: more than price_down"	minimum(1)
+//	@param			markets		query	[]string			true	"the list of the markets using for search"				Enums(wildberries, megamarket)				collectionFormat(ssv)	minLength(1)	example(megamarket+wildberries)
+//	@param			sample		query	integer				false	"the num of products' sample"							minimum(1)									default(1)
+//	@param			sort		query	string				false	"the type of products' sample sorting"					Enums(popular, pricedown, priceup, newly, rate)	default(popular)
+//	@param			no-image	query	integer				false	"the flag that defines 'Should image links be parsed?'"	Enums(0, 1)									default(1)
+//	@param			availability	query	string				false	"the stock status of the sample's products"	Enums(in_stock, all, preorder)	default(in_stock)
+//	@param			priority		query	string				false	"the priority of the async request's job in the queue"	Enums(high, normal, low)	default(normal)
+//	@param			merge		query	integer				false	"the flag that defines 'Should the samples be merged in one list?': it's sorted by the price for pricedown and priceup, the rest sorts interleave the products by their positions in the markets' samples"	Enums(0, 1)									default(0)
+//	@param			limit		query	integer				false	"the amount of the products that are collected from every market through the consecutive pages"	minimum(1)	maximum(300)
+//	@param			cursor		query	string				false	"the cursor of the previous response that continues it"
+//	@param			amount		query	integer				false	"the amount of the products in response's sample (min and max are the aliases of 15 and 100)"	minimum(1)	maximum(100)	default(15)
+//	@param			request		body	chttp.extraHeaders	true	"the headers that need to be included into the async response"
+//
+//	@success		202	{object}	chttp.JobResponse
+//	@failure		400	{object}	chttp.ResponseErr
+//	@failure		429	{object}	chttp.ResponseErr
+//	@header			429	{integer}	Retry-After	"the amount of the seconds after that the request can be repeated"
+//	@failure		500	{object}	chttp.ResponseErr
+//	@failure		503	{object}	chttp.ResponseErr
+//	@router			/products/filter/price/price-range/async [post]
+func (c *Controller) handlePriceRangeAsyncRequest(ctx echo.Context) error {
+	const filterType = "async-price-range-filter"
+
+	requestInfo, err := c.valid.validProductRequest(ctx,
+		c.valid.validQuery,
+		c.valid.validMarkets,
+		c.valid.validAmount,
+		c.valid.validSample,
+		c.valid.validSort,
+		c.valid.validNoImage,
+		c.valid.validAvailability,
+		c.valid.validMerge,
+		c.valid.validLimit,
+		c.valid.validPriceRange,
+		c.valid.validPriority,
+		c.valid.validExtraHeaders,
+		c.valid.validCursor,
+	)
+
+	if err != nil {
+		c.logger.Warn(fmt.Sprintf("error of the %v: %v", filterType, err))
+		return ctx.JSON(http.StatusBadRequest, ResponseErr{err.Error()})
+	}
+
+	return c.handleAsyncRequest(ctx, filterType, requestInfo, c.filter.FilterByPriceRangeAsync)
+}
+
+// handleExactPriceAsyncRequest defines the logic of handling the exact-price request
+// with the async processing.
+//
+//	@summary		async exact price filtering
+//	@description	this endpoint provides the products with the exactest prices to the client's price in the tolerance window in async mode
+//	@tags			Price-Filters
+//	@produce		json
+//
+//	@param			query		query	[]string			true	"the exact query string"								collectionFormat(ssv)						minLength(1)			example(iphone+11)
+//	@param			price		query	integer				true	"the value of exact price"								minimum(1)
+//	@param			tolerance	query	number				false	"the tolerance of the exact price: 0 is the exact match"						minimum(0)	default(5)
+//	@param			tolerance_type	query	string			false	"the type of the tolerance: percentage or absolute"	Enums(percent, absolute)	default(percent)
+//	@param			direction	query	string				false	"the direction of the tolerance window"					Enums(both, up, down)	default(up)
+//	@param			markets		query	[]string			true	"the list of the markets using for search"				Enums(wildberries, megamarket)				collectionFormat(ssv)	minLength(1)	example(megamarket+wildberries)
+//	@param			sample		query	integer				false	"the num of products' sample"							minimum(1)									default(1)
+//	@param			sort		query	string				false	"the type of products' sample sorting"					Enums(popular, pricedown, priceup, newly, rate)	default(popular)
+//	@param			no-image	query	integer				false	"the flag that defines 'Should image links be parsed?'"	Enums(0, 1)									default(1)
+//	@param			availability	query	string				false	"the stock status of the sample's products"	Enums(in_stock, all, preorder)	default(in_stock)
+//	@param			priority		query	string				false	"the priority of the async request's job in the queue"	Enums(high, normal, low)	default(normal)
+//	@param			merge		query	integer				false	"the flag that defines 'Should the samples be merged in one list?': it's sorted by the price for pricedown and priceup, the rest sorts interleave the products by their positions in the markets' samples"	Enums(0, 1)									default(0)
+//	@param			limit		query	integer				false	"the amount of the products that are collected from every market through the consecutive pages"	minimum(1)	maximum(300)
+//	@param			cursor		query	string				false	"the cursor of the previous response that continues it"
+//	@param			amount		query	integer				false	"the amount of the products in response's sample (min and max are the aliases of 15 and 100)"	minimum(1)	maximum(100)	default(15)
+//	@param			request		body	chttp.extraHeaders	true	"the headers that need to be included into the async response"
+//
+//	@success		202	{object}	chttp.JobResponse
+//	@failure		400	{object}	chttp.ResponseErr
+//	@failure		429	{object}	chttp.ResponseErr
+//	@header			429	{integer}	Retry-After	"the amount of the seconds after that the request can be repeated"
+//	@failure		500	{object}	chttp.ResponseErr
+//	@failure		503	{object}	chttp.ResponseErr
+//	@router			/products/filter/price/exact-price/async [post]
+func (c *Controller) handleExactPriceAsyncRequest(ctx echo.Context) error {
+	const filterType = "async-exact-price-filter"
+
+	requestInfo, err := c.valid.validProductRequest(ctx,
+		c.valid.validQuery,
+		c.valid.validMarkets,
+		c.valid.validAmount,
+		c.valid.validSample,
+		c.valid.validSort,
+		c.valid.validNoImage,
+		c.valid.validAvailability,
+		c.valid.validMerge,
+		c.valid.validLimit,
+		c.valid.validTolerance,
+		c.valid.validExactPrice,
+		c.valid.validPriority,
+		c.valid.validExtraHeaders,
+		c.valid.validCursor,
 	)
-	requestInfo.Async = true
 
 	if err != nil {
 		c.logger.Warn(fmt.Sprintf("error of the %v: %v", filterType, err))
 		return ctx.JSON(http.StatusBadRequest, ResponseErr{err.Error()})
 	}
 
-	go c.filter.FilterByBestPriceAsync(ctx, requestInfo)
+	return c.handleAsyncRequest(ctx, filterType, requestInfo, c.filter.FilterByExactPriceAsync)
+}
+
+// handleMarketsAsyncRequest defines the logic of handling the markets request
+// with the async processing.
+//
+//	@summary		async markets filtering
+//	@description	this endpoint provides the products from the requested marketplaces without the price filters in async mode
+//	@tags			Common-Filters
+//	@produce		json
+//
+//	@param			query		query	[]string			true	"the exact query string"								collectionFormat(ssv)						minLength(1)			example(iphone+11)
+//	@param			markets		query	[]string			true	"the list of the markets using for search"				Enums(wildberries, megamarket)				collectionFormat(ssv)	minLength(1)	example(megamarket+wildberries)
+//	@param			sample		query	integer				false	"the num of products' sample"							minimum(1)									default(1)
+//	@param			sort		query	string				false	"the type of products' sample sorting"					Enums(popular, pricedown, priceup, newly, rate)	default(popular)
+//	@param			no-image	query	integer				false	"the flag that defines 'Should image links be parsed?'"	Enums(0, 1)									default(1)
+//	@param			availability	query	string				false	"the stock status of the sample's products"	Enums(in_stock, all, preorder)	default(in_stock)
+//	@param			priority		query	string				false	"the priority of the async request's job in the queue"	Enums(high, normal, low)	default(normal)
+//	@param			merge		query	integer				false	"the flag that defines 'Should the samples be merged in one list?': it's sorted by the price for pricedown and priceup, the rest sorts interleave the products by their positions in the markets' samples"	Enums(0, 1)									default(0)
+//	@param			limit		query	integer				false	"the amount of the products that are collected from every market through the consecutive pages"	minimum(1)	maximum(300)
+//	@param			cursor		query	string				false	"the cursor of the previous response that continues it"
+//	@param			amount		query	integer				false	"the amount of the products in response's sample (min and max are the aliases of 15 and 100)"	minimum(1)	maximum(100)	default(15)
+//	@param			request		body	chttp.extraHeaders	true	"the headers that need to be included into the async response"
+//
+//	@success		202	{object}	chttp.JobResponse
+//	@failure		400	{object}	chttp.ResponseErr
+//	@failure		429	{object}	chttp.ResponseErr
+//	@header			429	{integer}	Retry-After	"the amount of the seconds after that the request can be repeated"
+//	@failure		500	{object}	chttp.ResponseErr
+//	@failure		503	{object}	chttp.ResponseErr
+//	@router			/products/filter/markets/async [post]
+func (c *Controller) handleMarketsAsyncRequest(ctx echo.Context) error {
+	const filterType = "async-markets-filter"
+
+	requestInfo, err := c.valid.validProductRequest(ctx,
+		c.valid.validQuery,
+		c.valid.validMarkets,
+		c.valid.validAmount,
+		c.valid.validSample,
+		c.valid.validSort,
+		c.valid.validNoImage,
+		c.valid.validAvailability,
+		c.valid.validMerge,
+		c.valid.validLimit,
+		c.valid.validPriority,
+		c.valid.validExtraHeaders,
+		c.valid.validCursor,
+	)
+
+	if err != nil {
+		c.logger.Warn(fmt.Sprintf("error of the %v: %v", filterType, err))
+		return ctx.JSON(http.StatusBadRequest, ResponseErr{err.Error()})
+	}
+
+	return c.handleAsyncRequest(ctx, filterType, requestInfo, c.filter.FilterByMarketsAsync)
+}
+
+// handleAsyncRequest starts the async job of the validated request and returns the job's view.
+func (c *Controller) handleAsyncRequest(ctx echo.Context, filterType string, requestInfo dto.ProductRequest,
+	filterAsync func(dto.ProductRequest) (dto.Job, error)) error {
+	requestInfo.Async = true
+
+	job, err := filterAsync(requestInfo)
+
+	if err != nil && errors.Is(err, services.ErrJobsQueueFull) {
+		c.logger.Warn(fmt.Sprintf("error of the %v: %v", filterType, err))
+		ctx.Response().Header().Set("Retry-After", fmt.Sprint(asyncRetryAfter))
+		return ctx.JSON(http.StatusTooManyRequests, ResponseErr{ErrJobsQueueFull.Error()})
+	} else if err != nil && errors.Is(err, services.ErrJobsStopped) {
+		c.logger.Warn(fmt.Sprintf("error of the %v: %v", filterType, err))
+		return ctx.JSON(http.StatusServiceUnavailable, ResponseErr{ErrServiceStopping.Error()})
+	} else if err != nil {
+		c.logger.Error(fmt.Sprintf("error of the %v: %v", filterType, err))
+		return ctx.JSON(http.StatusInternalServerError, ResponseErr{ErrServerHandling.Error()})
+	}
+
+	ctx.Response().Header().Set("Location", "/jobs/"+job.ID)
+
+	return ctx.JSON(http.StatusAccepted, NewJobResponse(job))
+}
+
+// handleJobRequest defines the logic of handling the async job's request:
+// it returns the job's state and, if the job has succeeded, its result.
+//
+//	@summary		async job's state getting
+//	@description	this endpoint provides the state of the async request's job and its result
+//	@tags			Async-Jobs
+//	@produce		json
+//
+//	@param			id	path		string	true	"the job's ID returned by the async request"
+//
+//	@success		200	{object}	chttp.JobResponse
+//	@failure		404	{object}	chttp.ResponseErr
+//	@router			/jobs/{id} [get]
+func (c *Controller) handleJobRequest(ctx echo.Context) error {
+	const opType = "job-state-getter"
+
+	job, err := c.filter.GetJob(ctx.Param("id"))
+
+	if err != nil {
+		c.logger.Warn(fmt.Sprintf("error of the %v: %v", opType, err))
+		return ctx.JSON(http.StatusNotFound, ResponseErr{ErrJobNotFound.Error()})
+	}
+
+	return ctx.JSON(http.StatusOK, NewJobResponse(job))
+}
+
+// handleJobCancelRequest defines the logic of handling the async job's cancelling request:
+// the queued job is cancelled at once and the running job is cancelled in the background.
+//
+//	@summary		async job's cancelling
+//	@description	this endpoint cancels the queued or the running async job: the terminal cancelled message is sent through the kafka
+//	@tags			Async-Jobs
+//	@produce		json
+//
+//	@param			id	path		string	true	"the job's ID returned by the async request"
+//
+//	@success		200	{object}	chttp.JobResponse	"the job was cancelled"
+//	@success		202	{object}	chttp.JobResponse	"the running job is being cancelled"
+//	@failure		404	{object}	chttp.ResponseErr
+//	@failure		409	{object}	chttp.ResponseErr
+//	@router			/jobs/{id} [delete]
+func (c *Controller) handleJobCancelRequest(ctx echo.Context) error {
+	const opType = "job-canceller"
+
+	job, err := c.filter.CancelJob(ctx.Param("id"))
+
+	if err != nil && errors.Is(err, services.ErrJobFinished) {
+		c.logger.Warn(fmt.Sprintf("error of the %v: %v", opType, err))
+		return ctx.JSON(http.StatusConflict, ResponseErr{ErrJobFinished.Error()})
+	} else if err != nil {
+		c.logger.Warn(fmt.Sprintf("error of the %v: %v", opType, err))
+		return ctx.JSON(http.StatusNotFound, ResponseErr{ErrJobNotFound.Error()})
+	}
+
+	if job.State != dto.JobCancelled {
+		return ctx.JSON(http.StatusAccepted, NewJobResponse(job))
+	}
 
-	return ctx.JSON(http.StatusOK, nil)
+	return ctx.JSON(http.StatusOK, NewJobResponse(job))
 }
@@ -1,13 +1,17 @@
 package chttp
 
 import (
+	"encoding/json"
+	"fmt"
 	"log/slog"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"strings"
 	"testing"
 
 	"github.com/MaKcm14/price-service/internal/entities/dto"
+	"github.com/MaKcm14/price-service/internal/services"
 	"github.com/MaKcm14/price-service/pkg/entities"
 	"github.com/labstack/echo/v4"
 	"github.com/stretchr/testify/assert"
@@ -48,12 +52,14 @@ func (s *handlersTestSuite) BeforeTest(suiteName, testName string) {
 	if testName == "TestHandlePriceRangeRequestPositiveCase" ||
 		testName == "TestHandlePriceRangeRequestNegativeCasesFilterInteraction" {
 		s.filterMock.On("FilterByPriceRange", mock.Anything, dto.ProductRequest{
-			Query:       "test query",
-			Sample:      1,
-			Amount:      "min",
-			Sort:        "popular",
-			FlagNoImage: true,
-			Markets:     []entities.Market{entities.Wildberries},
+			Query:        "test query",
+			Sample:       1,
+			Amount:       defaultAmount,
+			Sort:         "popular",
+			Availability: dto.InStockAvailability,
+			FlagNoImage:  true,
+			Markets:      []entities.Market{entities.Wildberries},
+			Headers:      map[string]string{},
 			PriceRange: dto.PriceRangeRequest{
 				PriceDown: 1000,
 				PriceUp:   5000,
@@ -62,13 +68,20 @@ func (s *handlersTestSuite) BeforeTest(suiteName, testName string) {
 	} else if testName == "TestHandleExactPriceRequestPositiveCase" ||
 		testName == "TestHandleExactPriceRequestNegativeCasesFilterInteraction" {
 		s.filterMock.On("FilterByExactPrice", mock.Anything, dto.ProductRequest{
-			Query:       "test query",
-			Sample:      1,
-			Amount:      "min",
-			Sort:        "popular",
-			FlagNoImage: true,
-			Markets:     []entities.Market{entities.Wildberries},
-			ExactPrice:  5000,
+			Query:        "test query",
+			Sample:       1,
+			Amount:       defaultAmount,
+			Sort:         "popular",
+			Availability: dto.InStockAvailability,
+			FlagNoImage:  true,
+			Markets:      []entities.Market{entities.Wildberries},
+			Headers:      map[string]string{},
+			ExactPrice:   5000,
+			Tolerance: dto.PriceToleranceRequest{
+				Value:     5,
+				Type:      dto.PercentTolerance,
+				Direction: dto.UpDirection,
+			},
 		})
 	}
 }
@@ -377,3 +390,232 @@ func (s *handlersTestSuite) TestHandleExactPriceRequestNegativeInputCases() {
 func TestContollerHandlers(t *testing.T) {
 	suite.Run(t, new(handlersTestSuite))
 }
+
+func TestHandleAsyncRequestsCases(t *testing.T) {
+	const query = "?query=test+query&markets=wildberries&price_down=1000&price_up=5000&price=5000"
+
+	type handler func(*Controller) func(echo.Context) error
+
+	handlers := map[string]handler{
+		"FilterByMarketsAsync":    func(c *Controller) func(echo.Context) error { return c.handleMarketsAsyncRequest },
+		"FilterByPriceRangeAsync": func(c *Controller) func(echo.Context) error { return c.handlePriceRangeAsyncRequest },
+		"FilterByBestPriceAsync":  func(c *Controller) func(echo.Context) error { return c.handleBestPriceAsyncRequest },
+		"FilterByExactPriceAsync": func(c *Controller) func(echo.Context) error { return c.handleExactPriceAsyncRequest },
+	}
+
+	tests := []struct {
+		name         string
+		query        string
+		serviceError bool
+		asyncError   error
+		wantStatus   int
+	}{
+		{"Positive Case: the job is queued", query, false, nil, http.StatusAccepted},
+		{"Negative Case: the job couldn't be created", query, true, nil, http.StatusInternalServerError},
+		{"Negative Case: the wrong request", "?markets=wildberries", false, nil, http.StatusBadRequest},
+		{"Negative Case: the jobs' queue is full", query, false, services.ErrJobsQueueFull, http.StatusTooManyRequests},
+		{"Negative Case: the service is stopping", query, false, services.ErrJobsStopped, http.StatusServiceUnavailable},
+	}
+
+	for method, getHandler := range handlers {
+		for _, tt := range tests {
+			t.Run(method+": "+tt.name, func(t *testing.T) {
+				filterMock := newProductsFilterMock(false, tt.serviceError)
+				filterMock.asyncError = tt.asyncError
+				filterMock.On(method, mock.Anything)
+
+				testContrObj := &Controller{
+					logger: slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError + 1})),
+					filter: filterMock,
+				}
+
+				request := httptest.NewRequest("POST", "/async"+tt.query,
+					strings.NewReader(`{"headers":[{"key":"chat-id","value":"42"}]}`))
+				request.Header.Set("Content-Type", "application/json")
+				recorder := httptest.NewRecorder()
+
+				err := getHandler(testContrObj)(echo.New().NewContext(request, recorder))
+
+				if assert.NoError(t, err) {
+					assert.Equal(t, tt.wantStatus, recorder.Code)
+				}
+
+				if tt.wantStatus == http.StatusTooManyRequests {
+					assert.Equal(t, fmt.Sprint(asyncRetryAfter), recorder.Header().Get("Retry-After"))
+				}
+
+				if tt.wantStatus != http.StatusAccepted {
+					return
+				}
+
+				var response JobResponse
+
+				json.Unmarshal(recorder.Body.Bytes(), &response)
+
+				assert.Equal(t, testJobID, response.ID)
+				assert.Equal(t, string(dto.JobQueued), response.State)
+				assert.Equal(t, "/jobs/"+testJobID, recorder.Header().Get("Location"))
+
+				if filterMock.AssertNumberOfCalls(t, method, 1) {
+					request := filterMock.Calls[0].Arguments.Get(0).(dto.ProductRequest)
+
+					assert.True(t, request.Async)
+					assert.Equal(t, dto.NormalPriority, request.Priority)
+					assert.Equal(t, map[string]string{"chat-id": "42"}, request.Headers)
+				}
+			})
+		}
+	}
+}
+
+func TestHandleJobRequestCases(t *testing.T) {
+	tests := []struct {
+		name       string
+		id         string
+		wantStatus int
+	}{
+		{"Positive Case: the job is found", testJobID, http.StatusOK},
+		{"Negative Case: the unknown job", "unknown-job-id", http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testContrObj := Controller{
+				logger: slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError + 1})),
+				filter: newProductsFilterMock(false, false),
+			}
+
+			recorder := httptest.NewRecorder()
+			ctx := echo.New().NewContext(httptest.NewRequest("GET", "/jobs/"+tt.id, nil), recorder)
+			ctx.SetParamNames("id")
+			ctx.SetParamValues(tt.id)
+
+			err := testContrObj.handleJobRequest(ctx)
+
+			if assert.NoError(t, err) {
+				assert.Equal(t, tt.wantStatus, recorder.Code)
+			}
+		})
+	}
+}
+
+func TestHandleJobCancelRequestCases(t *testing.T) {
+	tests := []struct {
+		name       string
+		id         string
+		wantStatus int
+		wantState  dto.JobState
+	}{
+		{"Positive Case: the queued job is cancelled", testJobID, http.StatusOK, dto.JobCancelled},
+		{"Positive Case: the running job is being cancelled", testRunningJobID, http.StatusAccepted, dto.JobRunning},
+		{"Negative Case: the job is already finished", testFinishedJobID, http.StatusConflict, ""},
+		{"Negative Case: the unknown job", "unknown-job-id", http.StatusNotFound, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testContrObj := Controller{
+				logger: slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError + 1})),
+				filter: newProductsFilterMock(false, false),
+			}
+
+			recorder := httptest.NewRecorder()
+			ctx := echo.New().NewContext(httptest.NewRequest("DELETE", "/jobs/"+tt.id, nil), recorder)
+			ctx.SetParamNames("id")
+			ctx.SetParamValues(tt.id)
+
+			err := testContrObj.handleJobCancelRequest(ctx)
+
+			if !assert.NoError(t, err) || !assert.Equal(t, tt.wantStatus, recorder.Code) || len(tt.wantState) == 0 {
+				return
+			}
+
+			var response JobResponse
+
+			json.Unmarshal(recorder.Body.Bytes(), &response)
+
+			assert.Equal(t, string(tt.wantState), response.State)
+		})
+	}
+}
+
+func TestSendProductsResponseCases(t *testing.T) {
+	okStatus := entities.MarketStatus{Market: nameTestMarket1, Status: entities.MarketStatusOK}
+	timeoutStatus := entities.MarketStatus{Market: "TestMarket2", Status: entities.MarketStatusTimeout, ErrorCode: "market_timeout"}
+
+	tests := []struct {
+		name         string
+		products     dto.FilterResponse
+		err          error
+		wantStatus   int
+		wantCache    string
+		wantStatuses map[string]entities.MarketStatus
+	}{
+		{
+			"Positive Case: every market has responded",
+			dto.FilterResponse{Statuses: []entities.MarketStatus{okStatus}},
+			nil, http.StatusOK, "public, max-age=43200", nil,
+		},
+		{
+			"Extreme Case: some market has failed",
+			dto.FilterResponse{Statuses: []entities.MarketStatus{okStatus, timeoutStatus}},
+			nil, http.StatusOK, "no-store", nil,
+		},
+		{
+			"Negative Case: every market has failed",
+			dto.FilterResponse{Statuses: []entities.MarketStatus{timeoutStatus}},
+			services.ErrGettingProducts, http.StatusBadGateway, "",
+			map[string]entities.MarketStatus{"testmarket2": timeoutStatus},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testContrObj := Controller{
+				logger: slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError + 1})),
+			}
+
+			recorder := httptest.NewRecorder()
+			ctx := echo.New().NewContext(httptest.NewRequest("GET", "/products/filter/markets", nil), recorder)
+
+			err := testContrObj.sendProductsResponse(ctx, "test-filter", tt.products, tt.err)
+
+			if !assert.NoError(t, err) || !assert.Equal(t, tt.wantStatus, recorder.Code) {
+				return
+			}
+			assert.Equal(t, tt.wantCache, recorder.Header().Get("Cache-Control"))
+
+			if tt.wantStatuses == nil {
+				return
+			}
+
+			var response ProductsErr
+
+			json.Unmarshal(recorder.Body.Bytes(), &response)
+
+			assert.Equal(t, ErrExternalServer.Error(), response.Error)
+			assert.Equal(t, tt.wantStatuses, response.Statuses)
+		})
+	}
+}
+
+func TestHandleMetricsCases(t *testing.T) {
+	t.Run("Positive Case: only the exposed metrics are returned", func(t *testing.T) {
+		testContrObj := Controller{}
+
+		recorder := httptest.NewRecorder()
+		ctx := echo.New().NewContext(httptest.NewRequest("GET", "/api/stats/metrics", nil), recorder)
+
+		if !assert.NoError(t, testContrObj.handleMetrics(ctx)) || !assert.Equal(t, http.StatusOK, recorder.Code) {
+			return
+		}
+
+		var metrics map[string]json.RawMessage
+
+		if assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &metrics)) {
+			assert.Contains(t, metrics, "async_jobs")
+			assert.NotContains(t, metrics, "cmdline")
+			assert.NotContains(t, metrics, "memstats")
+		}
+	})
+}
@@ -1,26 +1,172 @@
 package chttp
 
 import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
 	"strings"
+	"time"
 
+	"github.com/MaKcm14/price-service/internal/entities/dto"
+	"github.com/MaKcm14/price-service/internal/services"
 	"github.com/MaKcm14/price-service/pkg/entities"
 )
 
 // ProductResponse defines the response data.
 type ProductResponse struct {
-	Samples map[string]entities.ProductSample `json:"samples"`
+	Samples   map[string]entities.ProductSample `json:"samples"`
+	Statuses  map[string]entities.MarketStatus  `json:"statuses"`
+	Products  []entities.Product                `json:"products,omitempty"`
+	BestPrice *entities.BestPriceSummary        `json:"best_price,omitempty"`
+	Cursor    string                            `json:"cursor,omitempty"`
 }
 
-func NewProductResponse(samples []entities.ProductSample) ProductResponse {
+func NewProductResponse(response dto.FilterResponse) ProductResponse {
 	marketSamples := make(map[string]entities.ProductSample)
+	marketStatuses := make(map[string]entities.MarketStatus)
 
-	for _, sample := range samples {
+	for _, sample := range response.Samples {
 		marketSamples[strings.ToLower(sample.Market)] = sample
 	}
 
+	for _, status := range response.Statuses {
+		marketStatuses[strings.ToLower(status.Market)] = status
+	}
+
 	return ProductResponse{
-		Samples: marketSamples,
+		Samples:   marketSamples,
+		Statuses:  marketStatuses,
+		Products:  response.Products,
+		BestPrice: response.BestPrice,
+		Cursor:    encodeCursor(response.Cursor, response.CursorKey),
+	}
+}
+
+// JobResponse defines the async job's view.
+type JobResponse struct {
+	ID         string           `json:"job_id"`
+	State      string           `json:"state"`
+	Priority   string           `json:"priority,omitempty"`
+	CreatedAt  time.Time        `json:"created_at"`
+	StartedAt  *time.Time       `json:"started_at,omitempty"`
+	FinishedAt *time.Time       `json:"finished_at,omitempty"`
+	Error      string           `json:"error,omitempty"`
+	Result     *ProductResponse `json:"result,omitempty"`
+}
+
+// isDegraded checks whether some market has failed while the response was got.
+func isDegraded(response dto.FilterResponse) bool {
+	for _, status := range response.Statuses {
+		if status.Status != entities.MarketStatusOK {
+			return true
+		}
+	}
+	return false
+}
+
+func NewJobResponse(job dto.Job) JobResponse {
+	response := JobResponse{
+		ID:        job.ID,
+		State:     string(job.State),
+		Priority:  string(job.Priority),
+		CreatedAt: job.CreatedAt,
+	}
+
+	if !job.StartedAt.IsZero() {
+		response.StartedAt = &job.StartedAt
 	}
+
+	if !job.FinishedAt.IsZero() {
+		response.FinishedAt = &job.FinishedAt
+	}
+
+	if job.Err != nil && errors.Is(job.Err, services.ErrJobDeadline) {
+		response.Error = ErrJobTimeout.Error()
+	} else if job.Err != nil && errors.Is(job.Err, services.ErrJobCancelled) {
+		response.Error = ErrJobCancelled.Error()
+	} else if job.Err != nil && errors.Is(job.Err, services.ErrGettingProducts) {
+		response.Error = ErrExternalServer.Error()
+	} else if job.Err != nil {
+		response.Error = ErrServerHandling.Error()
+	}
+
+	if job.Result != nil {
+		result := NewProductResponse(*job.Result)
+		response.Result = &result
+	}
+
+	return response
+}
+
+// marketsNames defines the markets' names that are used in the requests.
+var marketsNames = map[string]entities.Market{
+	"wildberries": entities.Wildberries,
+	"megamarket":  entities.MegaMarket,
+}
+
+// marketCursorView defines the view of the market's position in the opaque cursor.
+type marketCursorView struct {
+	Page   int `json:"page"`
+	Offset int `json:"offset,omitempty"`
+}
+
+// cursorView defines the view of the opaque cursor: the key binds it to the request that it continues.
+type cursorView struct {
+	Key     string                      `json:"key"`
+	Markets map[string]marketCursorView `json:"markets"`
+}
+
+// encodeCursor returns the opaque view of the cursor with the request's key: it's empty if the cursor isn't set.
+func encodeCursor(cursor dto.Cursor, key string) string {
+	if len(cursor) == 0 {
+		return ""
+	}
+
+	view := cursorView{
+		Key:     key,
+		Markets: make(map[string]marketCursorView, len(cursor)),
+	}
+
+	for market, position := range cursor {
+		view.Markets[strings.ToLower(market.String())] = marketCursorView{
+			Page:   position.Page,
+			Offset: position.Offset,
+		}
+	}
+	buf, _ := json.Marshal(view)
+
+	return base64.RawURLEncoding.EncodeToString(buf)
+}
+
+// decodeCursor returns the cursor and the request's key from the cursor's opaque view.
+func decodeCursor(cursor string) (dto.Cursor, string, error) {
+	buf, err := base64.RawURLEncoding.DecodeString(cursor)
+
+	if err != nil {
+		return nil, "", ErrRequestInfo
+	}
+
+	var view cursorView
+
+	if err := json.Unmarshal(buf, &view); err != nil || len(view.Markets) == 0 {
+		return nil, "", ErrRequestInfo
+	}
+
+	res := make(dto.Cursor, len(view.Markets))
+
+	for name, position := range view.Markets {
+		market, flagExist := marketsNames[name]
+
+		if !flagExist || position.Page <= 0 || position.Offset < 0 {
+			return nil, "", ErrRequestInfo
+		}
+		res[market] = dto.MarketCursor{
+			Page:   position.Page,
+			Offset: position.Offset,
+		}
+	}
+
+	return res, view.Key, nil
 }
 
 // header defines the header data.
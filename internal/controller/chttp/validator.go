@@ -1,6 +1,8 @@
 package chttp
 
 import (
+	"errors"
+	"math"
 	"net/url"
 	"strconv"
 	"strings"
@@ -11,6 +13,32 @@ import (
 	"github.com/MaKcm14/price-service/pkg/entities"
 )
 
+const (
+	// defaultTop is the default amount of the cheapest products in the best-price response.
+	defaultTop = 10
+
+	// maxTop is the max amount of the cheapest products in the best-price response.
+	maxTop = 100
+
+	// defaultTolerance is the default tolerance of the exact price in percents.
+	defaultTolerance = 5
+
+	// maxPercentTolerance is the max tolerance of the exact price in percents.
+	maxPercentTolerance = 100
+
+	// maxAbsoluteTolerance is the max tolerance of the exact price in the currency's units.
+	maxAbsoluteTolerance = 1_000_000_000
+
+	// defaultAmount is the amount of the products that is got from every market's sample by default.
+	defaultAmount = 15
+
+	// maxAmount is the max amount of the products that can be got from every market's sample.
+	maxAmount = 100
+
+	// maxLimit is the max amount of the products that can be collected from every market.
+	maxLimit = 300
+)
+
 type queryOpt func(ctx echo.Context, request *dto.ProductRequest) error
 
 type (
@@ -82,13 +110,24 @@ func (v validator) validMarkets(ctx echo.Context, request *dto.ProductRequest) e
 }
 
 // validAmount validates the param "amount" that defines the amount of products.
+// The values "min" and "max" are kept as the aliases of the default and the max amount.
 func (v validator) validAmount(ctx echo.Context, request *dto.ProductRequest) error {
-	request.Amount = ctx.QueryParam("amount")
+	amount := ctx.QueryParam("amount")
 
-	if amt := request.Amount; amt != "max" && amt != "min" {
-		request.Amount = "min"
+	if amount == "max" {
+		request.Amount = maxAmount
+		return nil
 	}
 
+	count, err := strconv.Atoi(amount)
+
+	if count <= 0 || err != nil {
+		count = defaultAmount
+	} else if count > maxAmount {
+		count = maxAmount
+	}
+	request.Amount = count
+
 	return nil
 }
 
@@ -104,6 +143,47 @@ func (v validator) validSort(ctx echo.Context, request *dto.ProductRequest) erro
 	return nil
 }
 
+// validAvailability validates the param "availability" that defines the stock status of the sample's products.
+func (v validator) validAvailability(ctx echo.Context, request *dto.ProductRequest) error {
+	request.Availability = dto.AvailabilityType(ctx.QueryParam("availability"))
+
+	if availability := request.Availability; availability != dto.InStockAvailability &&
+		availability != dto.AllAvailability && availability != dto.PreorderAvailability {
+		request.Availability = dto.InStockAvailability
+	}
+
+	return nil
+}
+
+// validPriceRange validates the params "price_down" and "price_up" that define the price range's bounds.
+func (v validator) validPriceRange(ctx echo.Context, request *dto.ProductRequest) error {
+	priceDown, _ := strconv.Atoi(ctx.QueryParam("price_down"))
+	priceUp, _ := strconv.Atoi(ctx.QueryParam("price_up"))
+
+	if priceDown < 0 || priceUp <= 0 || priceUp < priceDown {
+		return ErrRequestInfo
+	}
+
+	request.PriceRange = dto.PriceRangeRequest{
+		PriceDown: priceDown,
+		PriceUp:   priceUp,
+	}
+
+	return nil
+}
+
+// validExactPrice validates the param "price" that defines the client's exact price.
+func (v validator) validExactPrice(ctx echo.Context, request *dto.ProductRequest) error {
+	exactPrice, _ := strconv.Atoi(ctx.QueryParam("price"))
+
+	if exactPrice <= 0 {
+		return ErrRequestInfo
+	}
+	request.ExactPrice = exactPrice
+
+	return nil
+}
+
 // validNoImage validates the param "no-image" that defines the presense the image-links in
 // the response.
 func (v validator) validNoImage(ctx echo.Context, request *dto.ProductRequest) error {
@@ -118,6 +198,123 @@ func (v validator) validNoImage(ctx echo.Context, request *dto.ProductRequest) e
 	return nil
 }
 
+// validTop validates the param "top" that defines the amount of the cheapest products across the markets.
+func (v validator) validTop(ctx echo.Context, request *dto.ProductRequest) error {
+	top, err := strconv.Atoi(ctx.QueryParam("top"))
+
+	if top <= 0 || err != nil {
+		top = defaultTop
+	} else if top > maxTop {
+		top = maxTop
+	}
+	request.Top = top
+
+	return nil
+}
+
+// validTolerance validates the params "tolerance", "tolerance_type" and "direction" that define
+// the window of the prices around the exact price. The tolerance that isn't set or isn't a number
+// is replaced with the default one, the zero tolerance defines the exact match of the price while
+// the negative, the non-finite tolerance or the tolerance that is more than the max of its type is rejected.
+func (v validator) validTolerance(ctx echo.Context, request *dto.ProductRequest) error {
+	request.Tolerance.Type = dto.ToleranceType(ctx.QueryParam("tolerance_type"))
+
+	if toleranceType := request.Tolerance.Type; toleranceType != dto.PercentTolerance && toleranceType != dto.AbsoluteTolerance {
+		request.Tolerance.Type = dto.PercentTolerance
+	}
+
+	tolerance, err := strconv.ParseFloat(ctx.QueryParam("tolerance"), 64)
+
+	if errors.Is(err, strconv.ErrRange) || math.IsNaN(tolerance) || math.IsInf(tolerance, 0) || tolerance < 0 {
+		return ErrRequestInfo
+	} else if err != nil {
+		tolerance = defaultTolerance
+	}
+
+	maxTolerance := float64(maxAbsoluteTolerance)
+
+	if request.Tolerance.Type == dto.PercentTolerance {
+		maxTolerance = maxPercentTolerance
+	}
+
+	if tolerance > maxTolerance {
+		return ErrRequestInfo
+	}
+	request.Tolerance.Value = tolerance
+
+	request.Tolerance.Direction = dto.ToleranceDirection(ctx.QueryParam("direction"))
+
+	if direction := request.Tolerance.Direction; direction != dto.BothDirection && direction != dto.UpDirection &&
+		direction != dto.DownDirection {
+		request.Tolerance.Direction = dto.UpDirection
+	}
+
+	return nil
+}
+
+// validLimit validates the param "limit" that defines the amount of the products that must be
+// collected from every market through the consecutive pages.
+func (v validator) validLimit(ctx echo.Context, request *dto.ProductRequest) error {
+	limit, err := strconv.Atoi(ctx.QueryParam("limit"))
+
+	if limit <= 0 || err != nil {
+		limit = 0
+	} else if limit > maxLimit {
+		limit = maxLimit
+	}
+	request.Limit = limit
+
+	return nil
+}
+
+// validCursor validates the param "cursor" that defines the continuation of the previous request.
+// The cursor is valid only with the limit and for the same request: it must be the last validator
+// because the cursor's key is checked against the whole validated request.
+func (v validator) validCursor(ctx echo.Context, request *dto.ProductRequest) error {
+	cursor := ctx.QueryParam("cursor")
+
+	if len(cursor) == 0 {
+		return nil
+	} else if request.Limit == 0 {
+		return ErrRequestInfo
+	}
+
+	res, key, err := decodeCursor(cursor)
+
+	if err != nil {
+		return err
+	} else if key != request.GetCursorKey() {
+		return ErrRequestInfo
+	}
+	request.Cursor = res
+
+	return nil
+}
+
+// validMerge validates the param "merge" that defines the merging of the markets' samples
+// in the one list of products.
+func (v validator) validMerge(ctx echo.Context, request *dto.ProductRequest) error {
+	request.FlagMerge = false
+
+	if ctx.QueryParam("merge") == "1" {
+		request.FlagMerge = true
+	}
+
+	return nil
+}
+
+// validPriority validates the param "priority" that defines the priority of the async request's job.
+func (v validator) validPriority(ctx echo.Context, request *dto.ProductRequest) error {
+	request.Priority = dto.JobPriority(ctx.QueryParam("priority"))
+
+	if priority := request.Priority; priority != dto.HighPriority && priority != dto.NormalPriority &&
+		priority != dto.LowPriority {
+		request.Priority = dto.NormalPriority
+	}
+
+	return nil
+}
+
 // validExtraHeaders validated the extra-headers for the async call.
 func (v validator) validExtraHeaders(ctx echo.Context, request *dto.ProductRequest) error {
 	headers := newExtraHeaders()
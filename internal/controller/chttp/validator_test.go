@@ -1,6 +1,7 @@
 package chttp
 
 import (
+	"encoding/base64"
 	"net/http"
 	"testing"
 
@@ -138,3 +139,387 @@ func TestIsDataSafeNegativeCases(t *testing.T) {
 		assert.False(t, flagDataSafe)
 	})
 }
+
+func TestValidMergeCases(t *testing.T) {
+	type args struct {
+		path string
+	}
+
+	tests := []struct {
+		name string
+		args args
+		want bool
+	}{
+		{"Positive Case: the merge flag is set", args{"http://localhost/products/filter/markets?merge=1"}, true},
+		{"Extreme Case: the merge flag isn't set", args{"http://localhost/products/filter/markets"}, false},
+		{"Negative Case: the wrong merge flag", args{"http://localhost/products/filter/markets?merge=yes"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var testValidatorObj = validator{}
+			var testRequestObj = dto.ProductRequest{}
+
+			request, err := http.NewRequest("GET", tt.args.path, nil)
+
+			if err != nil {
+				t.Fatal("error of the test configuration")
+			}
+
+			err = testValidatorObj.validMerge(echo.New().NewContext(request, nil), &testRequestObj)
+
+			if assert.NoError(t, err) {
+				assert.Equal(t, tt.want, testRequestObj.FlagMerge)
+			}
+		})
+	}
+}
+
+func TestValidTopCases(t *testing.T) {
+	type args struct {
+		path string
+	}
+
+	tests := []struct {
+		name string
+		args args
+		want int
+	}{
+		{"Positive Case: the correct top", args{"http://localhost/products/filter/price/best-price?top=5"}, 5},
+		{"Extreme Case: the top isn't set", args{"http://localhost/products/filter/price/best-price"}, defaultTop},
+		{"Extreme Case: the top is more than the max", args{"http://localhost/products/filter/price/best-price?top=1000"}, maxTop},
+		{"Negative Case: the wrong top", args{"http://localhost/products/filter/price/best-price?top=-1"}, defaultTop},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var testValidatorObj = validator{}
+			var testRequestObj = dto.ProductRequest{}
+
+			request, err := http.NewRequest("GET", tt.args.path, nil)
+
+			if err != nil {
+				t.Fatal("error of the test configuration")
+			}
+
+			err = testValidatorObj.validTop(echo.New().NewContext(request, nil), &testRequestObj)
+
+			if assert.NoError(t, err) {
+				assert.Equal(t, tt.want, testRequestObj.Top)
+			}
+		})
+	}
+}
+
+func TestValidToleranceCases(t *testing.T) {
+	type args struct {
+		path string
+	}
+
+	tests := []struct {
+		name string
+		args args
+		want dto.PriceToleranceRequest
+	}{
+		{"Positive Case: the absolute symmetric tolerance", args{"http://localhost/products/filter/price/exact-price?tolerance=300&tolerance_type=absolute&direction=both"},
+			dto.PriceToleranceRequest{Value: 300, Type: dto.AbsoluteTolerance, Direction: dto.BothDirection}},
+		{"Extreme Case: the tolerance isn't set", args{"http://localhost/products/filter/price/exact-price"},
+			dto.PriceToleranceRequest{Value: defaultTolerance, Type: dto.PercentTolerance, Direction: dto.UpDirection}},
+		{"Extreme Case: the zero tolerance is the exact match", args{"http://localhost/products/filter/price/exact-price?tolerance=0&direction=both"},
+			dto.PriceToleranceRequest{Value: 0, Type: dto.PercentTolerance, Direction: dto.BothDirection}},
+		{"Negative Case: the wrong tolerance's values", args{"http://localhost/products/filter/price/exact-price?tolerance=abc&tolerance_type=abs&direction=left"},
+			dto.PriceToleranceRequest{Value: defaultTolerance, Type: dto.PercentTolerance, Direction: dto.UpDirection}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var testValidatorObj = validator{}
+			var testRequestObj = dto.ProductRequest{}
+
+			request, err := http.NewRequest("GET", tt.args.path, nil)
+
+			if err != nil {
+				t.Fatal("error of the test configuration")
+			}
+
+			err = testValidatorObj.validTolerance(echo.New().NewContext(request, nil), &testRequestObj)
+
+			if assert.NoError(t, err) {
+				assert.Equal(t, tt.want, testRequestObj.Tolerance)
+			}
+		})
+	}
+}
+
+func TestValidToleranceNegativeCases(t *testing.T) {
+	type args struct {
+		path string
+	}
+
+	tests := []struct {
+		name string
+		args args
+	}{
+		{"Negative Case: the negative tolerance", args{"http://localhost/products/filter/price/exact-price?tolerance=-5"}},
+		{"Negative Case: the NaN tolerance", args{"http://localhost/products/filter/price/exact-price?tolerance=NaN"}},
+		{"Negative Case: the infinite tolerance", args{"http://localhost/products/filter/price/exact-price?tolerance=Inf&tolerance_type=absolute"}},
+		{"Negative Case: the overflowed tolerance", args{"http://localhost/products/filter/price/exact-price?tolerance=1e400&tolerance_type=absolute"}},
+		{"Negative Case: the too large absolute tolerance", args{"http://localhost/products/filter/price/exact-price?tolerance=1e308&tolerance_type=absolute"}},
+		{"Negative Case: the percent tolerance is more than 100", args{"http://localhost/products/filter/price/exact-price?tolerance=150"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var testValidatorObj = validator{}
+			var testRequestObj = dto.ProductRequest{}
+
+			request, err := http.NewRequest("GET", tt.args.path, nil)
+
+			if err != nil {
+				t.Fatal("error of the test configuration")
+			}
+
+			err = testValidatorObj.validTolerance(echo.New().NewContext(request, nil), &testRequestObj)
+
+			assert.ErrorIs(t, err, ErrRequestInfo)
+		})
+	}
+}
+
+func TestValidLimitCases(t *testing.T) {
+	type args struct {
+		path string
+	}
+
+	tests := []struct {
+		name string
+		args args
+		want int
+	}{
+		{"Positive Case: the correct limit", args{"http://localhost/products/filter/markets?limit=50"}, 50},
+		{"Extreme Case: the limit isn't set", args{"http://localhost/products/filter/markets"}, 0},
+		{"Extreme Case: the limit is more than the max", args{"http://localhost/products/filter/markets?limit=10000"}, maxLimit},
+		{"Negative Case: the wrong limit", args{"http://localhost/products/filter/markets?limit=-5"}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var testValidatorObj = validator{}
+			var testRequestObj = dto.ProductRequest{}
+
+			request, err := http.NewRequest("GET", tt.args.path, nil)
+
+			if err != nil {
+				t.Fatal("error of the test configuration")
+			}
+
+			err = testValidatorObj.validLimit(echo.New().NewContext(request, nil), &testRequestObj)
+
+			if assert.NoError(t, err) {
+				assert.Equal(t, tt.want, testRequestObj.Limit)
+			}
+		})
+	}
+}
+
+func TestValidCursorCases(t *testing.T) {
+	cursor := dto.Cursor{
+		entities.Wildberries: {Page: 3},
+		entities.MegaMarket:  {Page: 2, Offset: 7},
+	}
+	previous := dto.ProductRequest{Query: "iphone", Sort: dto.PopularSort, Limit: 10}
+	key := previous.GetCursorKey()
+
+	otherQuery := previous
+	otherQuery.Query = "ipad"
+
+	type args struct {
+		path  string
+		limit int
+	}
+
+	tests := []struct {
+		name    string
+		args    args
+		want    dto.Cursor
+		wantErr bool
+	}{
+		{"Positive Case: the cursor of the previous response", args{"http://localhost/products/filter/markets?cursor=" + encodeCursor(cursor, key), 10}, cursor, false},
+		{"Extreme Case: the cursor isn't set", args{"http://localhost/products/filter/markets", 0}, nil, false},
+		{"Negative Case: the cursor is set without the limit", args{"http://localhost/products/filter/markets?cursor=" + encodeCursor(cursor, key), 0}, nil, true},
+		{"Negative Case: the cursor of the other request", args{"http://localhost/products/filter/markets?cursor=" + encodeCursor(cursor, otherQuery.GetCursorKey()), 10}, nil, true},
+		{"Negative Case: the cursor isn't base64", args{"http://localhost/products/filter/markets?cursor=!!!", 10}, nil, true},
+		{"Negative Case: the cursor's market is unknown", args{"http://localhost/products/filter/markets?cursor=" +
+			base64.RawURLEncoding.EncodeToString([]byte(`{"key":"`+key+`","markets":{"ozon":{"page":1}}}`)), 10}, nil, true},
+		{"Negative Case: the cursor's page is wrong", args{"http://localhost/products/filter/markets?cursor=" +
+			base64.RawURLEncoding.EncodeToString([]byte(`{"key":"`+key+`","markets":{"wildberries":{"page":0}}}`)), 10}, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var testValidatorObj = validator{}
+			var testRequestObj = dto.ProductRequest{Query: previous.Query, Sort: previous.Sort, Limit: tt.args.limit}
+
+			request, err := http.NewRequest("GET", tt.args.path, nil)
+
+			if err != nil {
+				t.Fatal("error of the test configuration")
+			}
+
+			err = testValidatorObj.validCursor(echo.New().NewContext(request, nil), &testRequestObj)
+
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrRequestInfo)
+			} else if assert.NoError(t, err) {
+				assert.Equal(t, tt.want, testRequestObj.Cursor)
+			}
+		})
+	}
+}
+
+func TestValidAmountCases(t *testing.T) {
+	type args struct {
+		path string
+	}
+
+	tests := []struct {
+		name string
+		args args
+		want int
+	}{
+		{"Positive Case: the correct amount", args{"http://localhost/products/filter/markets?amount=40"}, 40},
+		{"Positive Case: the max alias", args{"http://localhost/products/filter/markets?amount=max"}, maxAmount},
+		{"Positive Case: the min alias", args{"http://localhost/products/filter/markets?amount=min"}, defaultAmount},
+		{"Extreme Case: the amount isn't set", args{"http://localhost/products/filter/markets"}, defaultAmount},
+		{"Extreme Case: the amount is more than the max", args{"http://localhost/products/filter/markets?amount=1000"}, maxAmount},
+		{"Negative Case: the wrong amount", args{"http://localhost/products/filter/markets?amount=-3"}, defaultAmount},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var testValidatorObj = validator{}
+			var testRequestObj = dto.ProductRequest{}
+
+			request, err := http.NewRequest("GET", tt.args.path, nil)
+
+			if err != nil {
+				t.Fatal("error of the test configuration")
+			}
+
+			err = testValidatorObj.validAmount(echo.New().NewContext(request, nil), &testRequestObj)
+
+			if assert.NoError(t, err) {
+				assert.Equal(t, tt.want, testRequestObj.Amount)
+			}
+		})
+	}
+}
+
+func TestValidAvailabilityCases(t *testing.T) {
+	type args struct {
+		path string
+	}
+
+	tests := []struct {
+		name string
+		args args
+		want dto.AvailabilityType
+	}{
+		{"Positive Case: the products of all the availabilities", args{"http://localhost/products/filter/markets?availability=all"}, dto.AllAvailability},
+		{"Positive Case: the preordered products", args{"http://localhost/products/filter/markets?availability=preorder"}, dto.PreorderAvailability},
+		{"Extreme Case: the availability isn't set", args{"http://localhost/products/filter/markets"}, dto.InStockAvailability},
+		{"Negative Case: the wrong availability", args{"http://localhost/products/filter/markets?availability=sold"}, dto.InStockAvailability},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var testValidatorObj = validator{}
+			var testRequestObj = dto.ProductRequest{}
+
+			request, err := http.NewRequest("GET", tt.args.path, nil)
+
+			if err != nil {
+				t.Fatal("error of the test configuration")
+			}
+
+			err = testValidatorObj.validAvailability(echo.New().NewContext(request, nil), &testRequestObj)
+
+			if assert.NoError(t, err) {
+				assert.Equal(t, tt.want, testRequestObj.Availability)
+			}
+		})
+	}
+}
+
+func TestValidPriceRangeCases(t *testing.T) {
+	tests := []struct {
+		name    string
+		path    string
+		want    dto.PriceRangeRequest
+		wantErr bool
+	}{
+		{"Positive Case: the correct bounds", "http://localhost/products/filter/price/price-range?price_down=100&price_up=500",
+			dto.PriceRangeRequest{PriceDown: 100, PriceUp: 500}, false},
+		{"Extreme Case: the equal bounds", "http://localhost/products/filter/price/price-range?price_down=500&price_up=500",
+			dto.PriceRangeRequest{PriceDown: 500, PriceUp: 500}, false},
+		{"Negative Case: the reversed bounds", "http://localhost/products/filter/price/price-range?price_down=500&price_up=100",
+			dto.PriceRangeRequest{}, true},
+		{"Negative Case: the bounds aren't set", "http://localhost/products/filter/price/price-range",
+			dto.PriceRangeRequest{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var testValidatorObj = validator{}
+			var testRequestObj = dto.ProductRequest{}
+
+			request, err := http.NewRequest("GET", tt.path, nil)
+
+			if err != nil {
+				t.Fatal("error of the test configuration")
+			}
+
+			err = testValidatorObj.validPriceRange(echo.New().NewContext(request, nil), &testRequestObj)
+
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrRequestInfo)
+			} else if assert.NoError(t, err) {
+				assert.Equal(t, tt.want, testRequestObj.PriceRange)
+			}
+		})
+	}
+}
+
+func TestValidExactPriceCases(t *testing.T) {
+	tests := []struct {
+		name    string
+		path    string
+		want    int
+		wantErr bool
+	}{
+		{"Positive Case: the correct price", "http://localhost/products/filter/price/exact-price?price=5000", 5000, false},
+		{"Negative Case: the zero price", "http://localhost/products/filter/price/exact-price?price=0", 0, true},
+		{"Negative Case: the price isn't set", "http://localhost/products/filter/price/exact-price", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var testValidatorObj = validator{}
+			var testRequestObj = dto.ProductRequest{}
+
+			request, err := http.NewRequest("GET", tt.path, nil)
+
+			if err != nil {
+				t.Fatal("error of the test configuration")
+			}
+
+			err = testValidatorObj.validExactPrice(echo.New().NewContext(request, nil), &testRequestObj)
+
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrRequestInfo)
+			} else if assert.NoError(t, err) {
+				assert.Equal(t, tt.want, testRequestObj.ExactPrice)
+			}
+		})
+	}
+}
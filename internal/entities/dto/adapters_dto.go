@@ -1,6 +1,13 @@
 package dto
 
-import "github.com/MaKcm14/price-service/pkg/entities"
+import (
+	"fmt"
+	"hash/fnv"
+	"math"
+	"strconv"
+
+	"github.com/MaKcm14/price-service/pkg/entities"
+)
 
 const (
 	PopularSort   SortType = "popular"
@@ -10,7 +17,29 @@ const (
 	RateSort      SortType = "rate"
 )
 
-type SortType string
+const (
+	PercentTolerance  ToleranceType = "percent"
+	AbsoluteTolerance ToleranceType = "absolute"
+)
+
+const (
+	BothDirection ToleranceDirection = "both"
+	UpDirection   ToleranceDirection = "up"
+	DownDirection ToleranceDirection = "down"
+)
+
+const (
+	InStockAvailability  AvailabilityType = "in_stock"
+	AllAvailability      AvailabilityType = "all"
+	PreorderAvailability AvailabilityType = "preorder"
+)
+
+type (
+	SortType           string
+	AvailabilityType   string
+	ToleranceType      string
+	ToleranceDirection string
+)
 
 // PriceRangeRequest defines the request data specially for price-range filter.
 type PriceRangeRequest struct {
@@ -18,20 +47,60 @@ type PriceRangeRequest struct {
 	PriceUp   int
 }
 
+// PriceToleranceRequest defines the request data specially for exact-price filter:
+// the window of the prices around the exact price.
+type PriceToleranceRequest struct {
+	Value     float64
+	Type      ToleranceType
+	Direction ToleranceDirection
+}
+
+// MarketCursor defines the position of the next products in the market's sample.
+type MarketCursor struct {
+	Page int
+
+	// Offset is the amount of the page's products (that fit the price bounds) that were already returned.
+	Offset int
+}
+
+// Cursor defines the positions of the next products in the markets' samples
+// that lets continue the previous request.
+type Cursor map[entities.Market]MarketCursor
+
 // ProductRequest defines the request data from the client to this service.
 type ProductRequest struct {
-	Query       string
-	Sample      int
-	Amount      string
-	Sort        SortType
-	FlagNoImage bool
-	Markets     []entities.Market
+	Query        string
+	Sample       int
+	Amount       int
+	Sort         SortType
+	Availability AvailabilityType
+	FlagNoImage  bool
+	FlagMerge    bool
+	Markets      []entities.Market
 
 	Async   bool
 	Headers map[string]string
 
+	// JobID is the ID of the async request's job: it's set only for the async requests.
+	JobID string
+
+	// Priority is the priority of the async request's job in the queue.
+	Priority JobPriority
+
 	PriceRange PriceRangeRequest
 	ExactPrice int
+	Tolerance  PriceToleranceRequest
+
+	// Top is the amount of the cheapest products across the markets for the best-price filter.
+	Top int
+
+	// Limit is the amount of the products that must be collected from every market through
+	// the consecutive pages: it's 0 if the only page must be got.
+	Limit int
+
+	// Cursor is the position of the request's continuation: it's nil for the new request.
+	// It's valid only with the limit and for the request with the same cursor's key.
+	Cursor Cursor
 }
 
 func NewProductRequest() ProductRequest {
@@ -39,5 +108,79 @@ func NewProductRequest() ProductRequest {
 		Markets: make([]entities.Market, 0, 15),
 		Headers: make(map[string]string),
 		Async:   false,
+
+		Availability: InStockAvailability,
+	}
+}
+
+// ShowNotAvailable checks whether the products that aren't in stock must be requested from the markets.
+func (a AvailabilityType) ShowNotAvailable() bool {
+	return a == AllAvailability || a == PreorderAvailability
+}
+
+// Allows checks whether the product with the availability fits the requested availability.
+// The products with the unknown availability are considered in stock: the markets don't show
+// the unavailable products by default.
+func (a AvailabilityType) Allows(availability entities.Availability) bool {
+	if a == AllAvailability {
+		return true
+	} else if a == PreorderAvailability {
+		return availability == entities.Preorder
 	}
+	return availability != entities.OutOfStock && availability != entities.Preorder
+}
+
+// GetCursorKey returns the key of the request's products' selection (the query, the sort and the filter's params):
+// the cursor can continue only the request with the same key.
+func (r ProductRequest) GetCursorKey() string {
+	hash := fnv.New64a()
+
+	fmt.Fprintf(hash, "%s|%s|%s|%t|%+v|%d|%+v|%d", r.Query, r.Sort, r.Availability, r.FlagMerge,
+		r.PriceRange, r.ExactPrice, r.Tolerance, r.Top)
+
+	return strconv.FormatUint(hash.Sum64(), 16)
+}
+
+// GetExactPriceRange returns the price range defined by the exact price and its tolerance window.
+func (r ProductRequest) GetExactPriceRange() PriceRangeRequest {
+	delta := r.Tolerance.Value
+
+	if r.Tolerance.Type == PercentTolerance {
+		delta = float64(r.ExactPrice) * r.Tolerance.Value / 100
+	}
+
+	priceRange := PriceRangeRequest{
+		PriceDown: r.ExactPrice,
+		PriceUp:   r.ExactPrice,
+	}
+
+	if r.Tolerance.Direction == BothDirection || r.Tolerance.Direction == DownDirection {
+		priceRange.PriceDown = int(math.Max(0, math.Floor(float64(r.ExactPrice)-delta)))
+	}
+
+	if r.Tolerance.Direction == BothDirection || r.Tolerance.Direction == UpDirection {
+		priceRange.PriceUp = int(math.Ceil(float64(r.ExactPrice) + delta))
+	}
+
+	return priceRange
+}
+
+// FilterResponse defines the result of the products' filtering got from the markets.
+type FilterResponse struct {
+	Samples  []entities.ProductSample
+	Statuses []entities.MarketStatus
+
+	// Products is the merged list of the samples' products that is set only in the merge mode
+	// or the cheapest products across the markets for the best-price filter.
+	Products []entities.Product
+
+	// BestPrice is the comparison of the markets' minimal prices that is set only for the best-price filter.
+	BestPrice *entities.BestPriceSummary
+
+	// Cursor is the position of the next products that is set only if the limit of the products was set.
+	// It's nil if all the markets' samples are over.
+	Cursor Cursor
+
+	// CursorKey is the key of the request that the cursor continues.
+	CursorKey string
 }
@@ -0,0 +1,82 @@
+package dto_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/MaKcm14/price-service/internal/entities/dto"
+	"github.com/MaKcm14/price-service/pkg/entities"
+)
+
+func TestGetExactPriceRangeCases(t *testing.T) {
+	tests := []struct {
+		name      string
+		tolerance dto.PriceToleranceRequest
+		want      dto.PriceRangeRequest
+	}{
+		{"Positive: the percentage symmetric window", dto.PriceToleranceRequest{Value: 10, Type: dto.PercentTolerance, Direction: dto.BothDirection}, dto.PriceRangeRequest{PriceDown: 900, PriceUp: 1100}},
+		{"Positive: the percentage upper window", dto.PriceToleranceRequest{Value: 5, Type: dto.PercentTolerance, Direction: dto.UpDirection}, dto.PriceRangeRequest{PriceDown: 1000, PriceUp: 1050}},
+		{"Positive: the absolute lower window", dto.PriceToleranceRequest{Value: 300, Type: dto.AbsoluteTolerance, Direction: dto.DownDirection}, dto.PriceRangeRequest{PriceDown: 700, PriceUp: 1000}},
+		{"Extreme: the lower bound is less than zero", dto.PriceToleranceRequest{Value: 2000, Type: dto.AbsoluteTolerance, Direction: dto.BothDirection}, dto.PriceRangeRequest{PriceDown: 0, PriceUp: 3000}},
+		{"Extreme: the fractional bounds are widened", dto.PriceToleranceRequest{Value: 0.5, Type: dto.AbsoluteTolerance, Direction: dto.BothDirection}, dto.PriceRangeRequest{PriceDown: 999, PriceUp: 1001}},
+		{"Extreme: the zero tolerance is the exact match", dto.PriceToleranceRequest{Value: 0, Type: dto.PercentTolerance, Direction: dto.BothDirection}, dto.PriceRangeRequest{PriceDown: 1000, PriceUp: 1000}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := dto.ProductRequest{
+				ExactPrice: 1000,
+				Tolerance:  tt.tolerance,
+			}
+
+			assert.Equal(t, tt.want, request.GetExactPriceRange())
+		})
+	}
+}
+
+func TestAvailabilityAllowsCases(t *testing.T) {
+	tests := []struct {
+		name         string
+		requested    dto.AvailabilityType
+		availability entities.Availability
+		want         bool
+	}{
+		{"Positive: the product in stock is allowed", dto.InStockAvailability, entities.InStock, true},
+		{"Positive: the product of the unknown availability is considered in stock", dto.InStockAvailability, entities.UnknownAvailability, true},
+		{"Negative: the product out of stock isn't allowed", dto.InStockAvailability, entities.OutOfStock, false},
+		{"Negative: the preordered product isn't in stock", dto.InStockAvailability, entities.Preorder, false},
+		{"Positive: the preordered product is allowed", dto.PreorderAvailability, entities.Preorder, true},
+		{"Negative: the product in stock isn't preordered", dto.PreorderAvailability, entities.InStock, false},
+		{"Positive: all the products are allowed", dto.AllAvailability, entities.OutOfStock, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.requested.Allows(tt.availability))
+		})
+	}
+}
+
+func TestGetCursorKeyCases(t *testing.T) {
+	request := dto.ProductRequest{Query: "iphone", Sort: dto.PopularSort, Limit: 10, Cursor: dto.Cursor{entities.Wildberries: {Page: 2}}}
+
+	t.Run("Positive Case: the continuation of the request has the same key", func(t *testing.T) {
+		next := request
+		next.Limit = 20
+		next.Cursor = dto.Cursor{entities.Wildberries: {Page: 3}}
+
+		assert.Equal(t, request.GetCursorKey(), next.GetCursorKey())
+	})
+
+	t.Run("Negative Case: the other request has the other key", func(t *testing.T) {
+		otherQuery, otherSort, otherPrice := request, request, request
+		otherQuery.Query = "ipad"
+		otherSort.Sort = dto.PriceUpSort
+		otherPrice.PriceRange = dto.PriceRangeRequest{PriceDown: 100, PriceUp: 200}
+
+		for _, other := range []dto.ProductRequest{otherQuery, otherSort, otherPrice} {
+			assert.NotEqual(t, request.GetCursorKey(), other.GetCursorKey())
+		}
+	})
+}
@@ -0,0 +1,50 @@
+package dto
+
+import "time"
+
+const (
+	JobQueued    JobState = "queued"
+	JobRunning   JobState = "running"
+	JobSucceeded JobState = "succeeded"
+	JobFailed    JobState = "failed"
+	JobCancelled JobState = "cancelled"
+)
+
+const (
+	HighPriority   JobPriority = "high"
+	NormalPriority JobPriority = "normal"
+	LowPriority    JobPriority = "low"
+)
+
+const (
+	// JobIDHeader is the key of the async message's header that contains the job's ID.
+	JobIDHeader = "job-id"
+
+	// JobStateHeader is the key of the async message's header that contains the job's terminal state.
+	JobStateHeader = "job-state"
+)
+
+// JobState defines the state of the async request's processing.
+type JobState string
+
+// JobPriority defines the order of the queued async jobs' processing.
+type JobPriority string
+
+// Job defines the async request's processing: its state and its result.
+type Job struct {
+	ID         string
+	State      JobState
+	Priority   JobPriority
+	CreatedAt  time.Time
+	StartedAt  time.Time
+	FinishedAt time.Time
+
+	// Result is set only if the job has succeeded and Err is set only if it has failed or was cancelled.
+	Result *FilterResponse
+	Err    error
+}
+
+// IsFinished checks whether the job's processing is over.
+func (j Job) IsFinished() bool {
+	return j.State == JobSucceeded || j.State == JobFailed || j.State == JobCancelled
+}
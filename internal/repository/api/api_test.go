@@ -1,11 +1,14 @@
 package api_test
 
 import (
+	"errors"
+	"fmt"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 
 	"github.com/MaKcm14/price-service/internal/repository/api"
+	"github.com/MaKcm14/price-service/internal/services"
 )
 
 func TestGetFiltersPositiveCases(t *testing.T) {
@@ -48,3 +51,23 @@ func TestGetFilterNegativeCases(t *testing.T) {
 		}, res)
 	})
 }
+
+func TestErrorCodePositiveCases(t *testing.T) {
+	t.Run("Positive: the wrapped api's error", func(t *testing.T) {
+		err := fmt.Errorf("error of the test service: %w", api.ErrJSONResponseParsing)
+
+		assert.Equal(t, "json_response_parsing", services.ErrorCode(err))
+	})
+
+	t.Run("Positive: the blocking error is prior to the response error", func(t *testing.T) {
+		err := fmt.Errorf("%w: %w", api.ErrServiceResponse, api.ErrServiceBlocked)
+
+		assert.Equal(t, "service_blocked", services.ErrorCode(err))
+	})
+}
+
+func TestErrorCodeNegativeCases(t *testing.T) {
+	t.Run("Negative: the error isn't the api's error", func(t *testing.T) {
+		assert.Equal(t, "unknown", services.ErrorCode(errors.New("test error")))
+	})
+}
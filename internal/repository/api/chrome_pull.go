@@ -0,0 +1,491 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/chromedp/chromedp"
+
+	"github.com/MaKcm14/price-service/pkg/entities"
+)
+
+// pull's defaults.
+const (
+	defaultBrowsers       = 1
+	defaultTabs           = 4
+	defaultAcquireTimeout = 10 * time.Second
+	defaultHoldTimeout    = 2 * time.Minute
+
+	// reapInterval is the interval of checking the leaked tabs.
+	reapInterval = 5 * time.Second
+)
+
+// TabsPull defines the pull of the browser's tabs that the pages are loaded in.
+type TabsPull interface {
+	// Acquire returns the context of the free tab bound to the request's context
+	// and the function that releases the tab.
+	Acquire(ctx context.Context) (context.Context, context.CancelFunc, error)
+}
+
+// PullOpt defines the optional settings of the ChromePull.
+type PullOpt func(*ChromePull)
+
+// WithBrowsers sets the amount of the browsers and the amount of the tabs in every browser.
+func WithBrowsers(browsers, tabs int) PullOpt {
+	return func(c *ChromePull) {
+		if browsers > 0 && tabs > 0 {
+			c.browsersCount, c.tabsCount = browsers, tabs
+		}
+	}
+}
+
+// WithAcquireTimeout sets the max time of waiting the free tab.
+func WithAcquireTimeout(timeout time.Duration) PullOpt {
+	return func(c *ChromePull) {
+		if timeout > 0 {
+			c.acquireTimeout = timeout
+		}
+	}
+}
+
+// WithHoldTimeout sets the max time of holding the tab: the tab that isn't released
+// in this time is considered leaked and is recycled.
+func WithHoldTimeout(timeout time.Duration) PullOpt {
+	return func(c *ChromePull) {
+		if timeout > 0 {
+			c.holdTimeout = timeout
+		}
+	}
+}
+
+// WithFailFast sets the pull's mode when the acquiring fails at once if there aren't any free tabs.
+func WithFailFast(failFast bool) PullOpt {
+	return func(c *ChromePull) {
+		c.failFast = failFast
+	}
+}
+
+// WithHeadless sets the mode of the browsers' running without the display.
+func WithHeadless(headless bool) PullOpt {
+	return func(c *ChromePull) {
+		c.execOpts = append(c.execOpts, chromedp.Flag("headless", headless))
+	}
+}
+
+// WithRemoteURL sets the DevTools' endpoint of the running browser which the pull connects to
+// instead of the starting the own browsers: the other browser's options are ignored.
+func WithRemoteURL(url string) PullOpt {
+	return func(c *ChromePull) {
+		c.remoteURL = url
+	}
+}
+
+// WithUserDataDir sets the directory of the browsers' profiles.
+func WithUserDataDir(dir string) PullOpt {
+	return func(c *ChromePull) {
+		if len(dir) != 0 {
+			c.execOpts = append(c.execOpts, chromedp.UserDataDir(dir))
+		}
+	}
+}
+
+// WithWindowSize sets the size of the browsers' windows.
+func WithWindowSize(width, height int) PullOpt {
+	return func(c *ChromePull) {
+		if width > 0 && height > 0 {
+			c.execOpts = append(c.execOpts, chromedp.WindowSize(width, height))
+		}
+	}
+}
+
+// WithProxy sets the proxy server of the browsers.
+func WithProxy(proxy string) PullOpt {
+	return func(c *ChromePull) {
+		if len(proxy) != 0 {
+			c.execOpts = append(c.execOpts, chromedp.ProxyServer(proxy))
+		}
+	}
+}
+
+// WithFlags sets the extra command line flags of the browsers in the "--name=value" or "--name" format.
+func WithFlags(flags []string) PullOpt {
+	return func(c *ChromePull) {
+		for _, flag := range flags {
+			name, value, flagValue := strings.Cut(strings.TrimLeft(flag, "-"), "=")
+
+			if len(name) == 0 {
+				continue
+			} else if flagValue {
+				c.execOpts = append(c.execOpts, chromedp.Flag(name, value))
+			} else {
+				c.execOpts = append(c.execOpts, chromedp.Flag(name, true))
+			}
+		}
+	}
+}
+
+// WithPullLogger sets the logger of the pull's events.
+func WithPullLogger(log *slog.Logger) PullOpt {
+	return func(c *ChromePull) {
+		c.logger = log
+	}
+}
+
+type (
+	// browser defines the allocated browser's instance: it's started with the first opened tab.
+	// Its mutex guards the browser's starting and it's never taken under the pull's mutex
+	// so the starting browser doesn't block the rest pull.
+	browser struct {
+		mut     sync.Mutex
+		ctx     context.Context
+		cancel  context.CancelFunc
+		started bool
+	}
+
+	// tab defines the browser's tab: its context is created with the first acquiring.
+	tab struct {
+		id         int
+		browser    int
+		ctx        context.Context
+		cancel     context.CancelFunc
+		acquiredAt time.Time
+	}
+)
+
+// ChromePull supports the safe opening and closing the connection with the instances of the browser
+// and shares their tabs between the concurrent requests.
+type ChromePull struct {
+	mut    sync.Mutex
+	logger *slog.Logger
+
+	browsersCount  int
+	tabsCount      int
+	acquireTimeout time.Duration
+	holdTimeout    time.Duration
+	failFast       bool
+
+	browsers []*browser
+	idle     chan *tab
+	inUse    map[int]*tab
+	nextID   int
+
+	waiting   int
+	acquired  int64
+	recycled  int64
+	exhausted int64
+
+	// execOpts are the options of the started browsers and remoteURL is the endpoint of the remote browser.
+	execOpts  []chromedp.ExecAllocatorOption
+	remoteURL string
+
+	// allocate, start and open define the interaction with the browser's driver.
+	allocate func() (context.Context, context.CancelFunc)
+	start    func(ctx context.Context) error
+	open     func(browserCtx context.Context) (context.Context, context.CancelFunc)
+
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+func NewChromePull(opts ...PullOpt) *ChromePull {
+	pull := &ChromePull{
+		logger:         slog.New(slog.NewTextHandler(io.Discard, nil)),
+		browsersCount:  defaultBrowsers,
+		tabsCount:      defaultTabs,
+		acquireTimeout: defaultAcquireTimeout,
+		holdTimeout:    defaultHoldTimeout,
+		inUse:          make(map[int]*tab),
+		execOpts:       append([]chromedp.ExecAllocatorOption{}, chromedp.DefaultExecAllocatorOptions[:]...),
+		start: func(ctx context.Context) error {
+			return chromedp.Run(ctx)
+		},
+		open: func(browserCtx context.Context) (context.Context, context.CancelFunc) {
+			return chromedp.NewContext(browserCtx)
+		},
+		done: make(chan struct{}),
+	}
+	pull.allocate = pull.allocateBrowser
+
+	for _, opt := range opts {
+		opt(pull)
+	}
+	pull.init()
+
+	go pull.reap()
+
+	return pull
+}
+
+// allocateBrowser allocates the context of the new browser's instance:
+// it's started by the pull or connected to the remote browser.
+func (c *ChromePull) allocateBrowser() (context.Context, context.CancelFunc) {
+	var allocCtx context.Context
+	var cancelAlloc context.CancelFunc
+
+	if len(c.remoteURL) != 0 {
+		allocCtx, cancelAlloc = chromedp.NewRemoteAllocator(context.Background(), c.remoteURL)
+	} else {
+		allocCtx, cancelAlloc = chromedp.NewExecAllocator(context.Background(), c.execOpts...)
+	}
+	ctx, cancel := chromedp.NewContext(allocCtx)
+
+	return ctx, func() {
+		cancel()
+		cancelAlloc()
+	}
+}
+
+// init allocates the browsers and fills the pull with their tabs.
+func (c *ChromePull) init() {
+	c.idle = make(chan *tab, c.browsersCount*c.tabsCount)
+	c.browsers = make([]*browser, 0, c.browsersCount)
+
+	for i := 0; i != c.browsersCount; i++ {
+		ctx, cancel := c.allocate()
+		c.browsers = append(c.browsers, &browser{ctx: ctx, cancel: cancel})
+
+		for j := 0; j != c.tabsCount; j++ {
+			c.idle <- c.newTab(i)
+		}
+	}
+}
+
+// newTab returns the new tab of the browser. It must be called under the pull's mutex
+// or before the pull is shared.
+func (c *ChromePull) newTab(browser int) *tab {
+	c.nextID++
+	return &tab{id: c.nextID, browser: browser}
+}
+
+// take takes the free tab from the pull: it waits the free tab until the acquire's timeout
+// or fails at once in the fail-fast mode.
+func (c *ChromePull) take(ctx context.Context) (*tab, error) {
+	select {
+	case <-c.done:
+		return nil, ErrChromePullClosed
+	case t := <-c.idle:
+		return t, nil
+	default:
+	}
+
+	c.mut.Lock()
+	if c.failFast {
+		c.exhausted++
+		c.mut.Unlock()
+		return nil, ErrChromePullExhausted
+	}
+	c.waiting++
+	c.mut.Unlock()
+
+	defer func() {
+		c.mut.Lock()
+		c.waiting--
+		c.mut.Unlock()
+	}()
+
+	timer := time.NewTimer(c.acquireTimeout)
+	defer timer.Stop()
+
+	select {
+	case t := <-c.idle:
+		return t, nil
+
+	case <-timer.C:
+		c.mut.Lock()
+		c.exhausted++
+		c.mut.Unlock()
+		return nil, fmt.Errorf("%w: timeout %v", ErrChromePullExhausted, c.acquireTimeout)
+
+	case <-ctx.Done():
+		return nil, fmt.Errorf("%w: %v", ErrConnectionClosed, ctx.Err())
+
+	case <-c.done:
+		return nil, ErrChromePullClosed
+	}
+}
+
+// prepare checks the tab's health and opens it if it's needed: the crashed tabs and browsers are recycled.
+func (c *ChromePull) prepare(t *tab) error {
+	b := c.browsers[t.browser]
+
+	b.mut.Lock()
+	defer b.mut.Unlock()
+
+	select {
+	case <-c.done:
+		return ErrChromePullClosed
+	default:
+	}
+
+	if b.ctx.Err() != nil {
+		c.logger.Warn(fmt.Sprintf("the browser %d has crashed and is recycled", t.browser))
+		b.cancel()
+		b.ctx, b.cancel = c.allocate()
+		b.started = false
+		c.countRecycled()
+	}
+
+	if t.ctx != nil && t.ctx.Err() != nil {
+		c.logger.Warn(fmt.Sprintf("the tab %d has crashed and is recycled", t.id))
+		t.cancel()
+		t.ctx, t.cancel = nil, nil
+		c.countRecycled()
+	}
+
+	if !b.started {
+		if err := c.start(b.ctx); err != nil {
+			return fmt.Errorf("%w: %v", ErrChromeDriver, err)
+		}
+		b.started = true
+	}
+
+	if t.ctx == nil {
+		t.ctx, t.cancel = c.open(b.ctx)
+	}
+
+	return nil
+}
+
+// countRecycled counts the recycled tab or browser.
+func (c *ChromePull) countRecycled() {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	c.recycled++
+}
+
+// Acquire returns the context of the free tab bound to the request's context
+// and the function that releases the tab: it must be called when the tab isn't needed.
+func (c *ChromePull) Acquire(ctx context.Context) (context.Context, context.CancelFunc, error) {
+	t, err := c.take(ctx)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := c.prepare(t); err != nil {
+		c.idle <- t
+		return nil, nil, err
+	}
+
+	c.mut.Lock()
+	t.acquiredAt = time.Now()
+	c.inUse[t.id] = t
+	c.acquired++
+	c.mut.Unlock()
+
+	tabCtx, cancel := BindContext(t.ctx, ctx)
+	once := sync.Once{}
+
+	return tabCtx, func() {
+		once.Do(func() {
+			cancel()
+			c.release(t)
+		})
+	}, nil
+}
+
+// release returns the tab to the pull: the tab that was already recycled as leaked is ignored.
+func (c *ChromePull) release(t *tab) {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	if _, flagExist := c.inUse[t.id]; !flagExist {
+		return
+	}
+	delete(c.inUse, t.id)
+
+	select {
+	case <-c.done:
+		return
+	default:
+		c.idle <- t
+	}
+}
+
+// reap recycles the tabs that are held longer than the hold's timeout.
+func (c *ChromePull) reap() {
+	ticker := time.NewTicker(min(reapInterval, c.holdTimeout))
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.done:
+			return
+		case now := <-ticker.C:
+			c.recycleLeaked(now)
+		}
+	}
+}
+
+// recycleLeaked closes the tabs that are held longer than the hold's timeout
+// and replaces them with the new ones.
+func (c *ChromePull) recycleLeaked(now time.Time) {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	for id, t := range c.inUse {
+		if now.Sub(t.acquiredAt) < c.holdTimeout {
+			continue
+		}
+		c.logger.Warn(fmt.Sprintf("the tab %d is leaked and is recycled", id))
+
+		delete(c.inUse, id)
+
+		if t.cancel != nil {
+			t.cancel()
+		}
+		c.idle <- c.newTab(t.browser)
+		c.recycled++
+	}
+}
+
+// Stats returns the current state of the pull.
+func (c *ChromePull) Stats() entities.DriverStats {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	return entities.DriverStats{
+		Browsers:  c.browsersCount,
+		Tabs:      c.browsersCount * c.tabsCount,
+		Idle:      len(c.idle),
+		InUse:     len(c.inUse),
+		Waiting:   c.waiting,
+		Acquired:  c.acquired,
+		Recycled:  c.recycled,
+		Exhausted: c.exhausted,
+	}
+}
+
+// Close closes all the browsers and their tabs.
+func (c *ChromePull) Close() {
+	c.closeOnce.Do(func() {
+		c.mut.Lock()
+		close(c.done)
+
+		for _, t := range c.inUse {
+			if t.cancel != nil {
+				t.cancel()
+			}
+		}
+
+		for len(c.idle) != 0 {
+			if t := <-c.idle; t.cancel != nil {
+				t.cancel()
+			}
+		}
+
+		c.mut.Unlock()
+
+		for _, b := range c.browsers {
+			b.mut.Lock()
+			b.cancel()
+			b.mut.Unlock()
+		}
+	})
+}
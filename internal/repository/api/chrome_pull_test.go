@@ -0,0 +1,247 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// withTestDriver replaces the browser's driver with the contexts that don't start any browser.
+func withTestDriver() PullOpt {
+	return func(c *ChromePull) {
+		c.allocate = func() (context.Context, context.CancelFunc) {
+			return context.WithCancel(context.Background())
+		}
+		c.start = func(ctx context.Context) error {
+			return nil
+		}
+		c.open = func(browserCtx context.Context) (context.Context, context.CancelFunc) {
+			return context.WithCancel(browserCtx)
+		}
+	}
+}
+
+func TestChromePullPositiveCases(t *testing.T) {
+	t.Run("Positive Case: the tab is acquired and released", func(t *testing.T) {
+		pull := NewChromePull(withTestDriver(), WithBrowsers(2, 2))
+		defer pull.Close()
+
+		ctx, release, err := pull.Acquire(context.Background())
+
+		if assert.NoError(t, err) {
+			assert.NoError(t, ctx.Err())
+			assert.Equal(t, 1, pull.Stats().InUse)
+			assert.Equal(t, 3, pull.Stats().Idle)
+
+			release()
+			release()
+
+			assert.Error(t, ctx.Err())
+			assert.Equal(t, 0, pull.Stats().InUse)
+			assert.Equal(t, 4, pull.Stats().Idle)
+			assert.Equal(t, int64(1), pull.Stats().Acquired)
+		}
+	})
+
+	t.Run("Positive Case: the waiting request gets the released tab", func(t *testing.T) {
+		pull := NewChromePull(withTestDriver(), WithBrowsers(1, 1), WithAcquireTimeout(time.Second))
+		defer pull.Close()
+
+		_, release, err := pull.Acquire(context.Background())
+
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		go func() {
+			time.Sleep(20 * time.Millisecond)
+			release()
+		}()
+
+		_, releaseNext, err := pull.Acquire(context.Background())
+
+		if assert.NoError(t, err) {
+			releaseNext()
+		}
+	})
+
+	t.Run("Positive Case: the crashed tab is recycled", func(t *testing.T) {
+		pull := NewChromePull(withTestDriver(), WithBrowsers(1, 1))
+		defer pull.Close()
+
+		_, release, _ := pull.Acquire(context.Background())
+		release()
+
+		crashed := <-pull.idle
+		crashed.cancel()
+		pull.idle <- crashed
+
+		ctx, release, err := pull.Acquire(context.Background())
+
+		if assert.NoError(t, err) {
+			assert.NoError(t, ctx.Err())
+			assert.Equal(t, int64(1), pull.Stats().Recycled)
+			release()
+		}
+	})
+
+	t.Run("Positive Case: the crashed browser is recycled", func(t *testing.T) {
+		var crashes []context.CancelFunc
+		var cancelled int
+
+		pull := NewChromePull(withTestDriver(), WithBrowsers(1, 1))
+		defer pull.Close()
+
+		// the browser's context is cancelled by the crash without calling the browser's cancel.
+		pull.allocate = func() (context.Context, context.CancelFunc) {
+			ctx, cancel := context.WithCancel(context.Background())
+			crashes = append(crashes, cancel)
+
+			return ctx, func() {
+				cancelled++
+				cancel()
+			}
+		}
+		pull.browsers[0].ctx, pull.browsers[0].cancel = pull.allocate()
+		crashes[0]()
+
+		ctx, release, err := pull.Acquire(context.Background())
+
+		if assert.NoError(t, err) {
+			assert.NoError(t, ctx.Err())
+			assert.Equal(t, 1, cancelled)
+			assert.Equal(t, int64(1), pull.Stats().Recycled)
+			release()
+		}
+	})
+
+	t.Run("Positive Case: the starting browser doesn't block the pull", func(t *testing.T) {
+		started := make(chan struct{})
+		unblock := make(chan struct{})
+
+		pull := NewChromePull(withTestDriver(), WithBrowsers(1, 2))
+		defer pull.Close()
+
+		pull.start = func(ctx context.Context) error {
+			close(started)
+			<-unblock
+			return nil
+		}
+
+		go func() {
+			if _, release, err := pull.Acquire(context.Background()); err == nil {
+				release()
+			}
+		}()
+		<-started
+
+		stats := make(chan struct{})
+
+		go func() {
+			pull.Stats()
+			close(stats)
+		}()
+
+		select {
+		case <-stats:
+		case <-time.After(time.Second):
+			t.Error("the pull is blocked by the starting browser")
+		}
+		close(unblock)
+	})
+
+	t.Run("Positive Case: the leaked tab is recycled", func(t *testing.T) {
+		pull := NewChromePull(withTestDriver(), WithBrowsers(1, 1), WithHoldTimeout(time.Minute))
+		defer pull.Close()
+
+		ctx, release, _ := pull.Acquire(context.Background())
+		pull.recycleLeaked(time.Now().Add(time.Hour))
+
+		assert.Error(t, ctx.Err())
+		assert.Equal(t, 0, pull.Stats().InUse)
+		assert.Equal(t, 1, pull.Stats().Idle)
+		assert.Equal(t, int64(1), pull.Stats().Recycled)
+
+		release()
+
+		assert.Equal(t, 1, pull.Stats().Idle)
+	})
+}
+
+func TestChromePullNegativeCases(t *testing.T) {
+	t.Run("Negative Case: the pull is exhausted in the fail-fast mode", func(t *testing.T) {
+		pull := NewChromePull(withTestDriver(), WithBrowsers(1, 1), WithFailFast(true))
+		defer pull.Close()
+
+		_, release, _ := pull.Acquire(context.Background())
+		defer release()
+
+		_, _, err := pull.Acquire(context.Background())
+
+		assert.True(t, errors.Is(err, ErrChromePullExhausted))
+		assert.Equal(t, int64(1), pull.Stats().Exhausted)
+	})
+
+	t.Run("Negative Case: the acquire's timeout is exceeded", func(t *testing.T) {
+		pull := NewChromePull(withTestDriver(), WithBrowsers(1, 1), WithAcquireTimeout(20*time.Millisecond))
+		defer pull.Close()
+
+		_, release, _ := pull.Acquire(context.Background())
+		defer release()
+
+		_, _, err := pull.Acquire(context.Background())
+
+		assert.True(t, errors.Is(err, ErrChromePullExhausted))
+	})
+
+	t.Run("Negative Case: the request is cancelled while waiting", func(t *testing.T) {
+		pull := NewChromePull(withTestDriver(), WithBrowsers(1, 1))
+		defer pull.Close()
+
+		_, release, _ := pull.Acquire(context.Background())
+		defer release()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, _, err := pull.Acquire(ctx)
+
+		assert.True(t, errors.Is(err, ErrConnectionClosed))
+	})
+
+	t.Run("Negative Case: the pull is closed", func(t *testing.T) {
+		pull := NewChromePull(withTestDriver())
+		pull.Close()
+
+		_, _, err := pull.Acquire(context.Background())
+
+		assert.True(t, errors.Is(err, ErrChromePullClosed))
+	})
+}
+
+func TestChromePullBrowserOptionsCases(t *testing.T) {
+	t.Run("Positive Case: the extra flags are added", func(t *testing.T) {
+		pull := NewChromePull(withTestDriver())
+		defer pull.Close()
+
+		count := len(pull.execOpts)
+		WithFlags([]string{"--disable-gpu", "--lang=ru", "--"})(pull)
+
+		assert.Equal(t, count+2, len(pull.execOpts))
+	})
+
+	t.Run("Extreme Case: the empty options are ignored", func(t *testing.T) {
+		pull := NewChromePull(withTestDriver())
+		defer pull.Close()
+
+		count := len(pull.execOpts)
+		WithUserDataDir("")(pull)
+		WithProxy("")(pull)
+		WithWindowSize(0, 1080)(pull)
+
+		assert.Equal(t, count, len(pull.execOpts))
+	})
+}
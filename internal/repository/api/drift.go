@@ -0,0 +1,108 @@
+package api
+
+import (
+	"expvar"
+	"fmt"
+	"log/slog"
+	"sync"
+)
+
+const (
+	// DriftThreshold is the fraction of the response's products with the missing critical fields
+	// from which the market's schema is considered drifted.
+	DriftThreshold = 0.5
+)
+
+// driftMetric counts the checked and the drifted responses of every market.
+var driftMetric = expvar.NewMap("schema_drift")
+
+// DriftReport defines the amount of the response's products with the missing critical fields.
+type DriftReport struct {
+	Total      int
+	Incomplete int
+	Missing    map[string]int
+}
+
+func NewDriftReport() DriftReport {
+	return DriftReport{
+		Missing: make(map[string]int),
+	}
+}
+
+// Add adds the product with its missing critical fields to the report.
+func (r *DriftReport) Add(missingFields ...string) {
+	r.Total++
+
+	if len(missingFields) != 0 {
+		r.Incomplete++
+	}
+
+	for _, field := range missingFields {
+		r.Missing[field]++
+	}
+}
+
+// Fraction returns the fraction of the products with the missing critical fields.
+func (r DriftReport) Fraction() float64 {
+	if r.Total == 0 {
+		return 0
+	}
+	return float64(r.Incomplete) / float64(r.Total)
+}
+
+// DriftDetector detects the drift of the market's response's schema: the critical fields that
+// became empty for the most of the products usually mean the renamed or moved fields.
+type DriftDetector struct {
+	mut       sync.Mutex
+	logger    *slog.Logger
+	market    string
+	threshold float64
+
+	checked int64
+	drifted int64
+}
+
+func NewDriftDetector(log *slog.Logger, market string, threshold float64) *DriftDetector {
+	return &DriftDetector{
+		logger:    log,
+		market:    market,
+		threshold: threshold,
+	}
+}
+
+// Check checks the response's report: it returns the ErrSchemaDrift if the fraction of the products
+// with the missing critical fields crosses the threshold.
+func (d *DriftDetector) Check(report DriftReport) error {
+	const serviceType = "api.service.schema-drift-detector"
+
+	if report.Total == 0 {
+		return nil
+	}
+
+	d.mut.Lock()
+	defer d.mut.Unlock()
+
+	d.checked++
+	driftMetric.Add(d.market+".checked", 1)
+
+	if report.Fraction() < d.threshold {
+		return nil
+	}
+
+	d.drifted++
+	driftMetric.Add(d.market+".drifted", 1)
+
+	err := fmt.Errorf("%w: %s: %d of %d products have got the missing fields %v",
+		ErrSchemaDrift, d.market, report.Incomplete, report.Total, report.Missing)
+	d.logger.Error(fmt.Sprintf("error of the %s: %v", serviceType, err))
+
+	return err
+}
+
+// Stats returns the amount of the checked and the drifted responses.
+func (d *DriftDetector) Stats() (checked, drifted int64) {
+	d.mut.Lock()
+	defer d.mut.Unlock()
+
+	return d.checked, d.drifted
+}
@@ -0,0 +1,53 @@
+package api
+
+import (
+	"log/slog"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDriftDetectorPositiveCases(t *testing.T) {
+	t.Run("Positive Case: the fraction is below the threshold", func(t *testing.T) {
+		detector := NewDriftDetector(slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError + 1})),
+			"TestMarket", DriftThreshold)
+		report := NewDriftReport()
+
+		report.Add()
+		report.Add()
+		report.Add("name")
+
+		assert.NoError(t, detector.Check(report))
+
+		checked, drifted := detector.Stats()
+		assert.Equal(t, int64(1), checked)
+		assert.Equal(t, int64(0), drifted)
+	})
+
+	t.Run("Extreme Case: the empty response isn't checked", func(t *testing.T) {
+		detector := NewDriftDetector(slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError + 1})),
+			"TestMarket", DriftThreshold)
+
+		assert.NoError(t, detector.Check(NewDriftReport()))
+
+		checked, _ := detector.Stats()
+		assert.Equal(t, int64(0), checked)
+	})
+}
+
+func TestDriftDetectorNegativeCase(t *testing.T) {
+	detector := NewDriftDetector(slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError + 1})),
+		"TestMarket", DriftThreshold)
+	report := NewDriftReport()
+
+	report.Add("name", "price")
+	report.Add("name")
+	report.Add()
+
+	assert.ErrorIs(t, detector.Check(report), ErrSchemaDrift)
+	assert.Equal(t, map[string]int{"name": 2, "price": 1}, report.Missing)
+
+	_, drifted := detector.Stats()
+	assert.Equal(t, int64(1), drifted)
+}
@@ -1,12 +1,24 @@
 package api
 
-import "errors"
+import "github.com/MaKcm14/price-service/internal/services"
 
+// The errors of the markets' apis are defined by the services' layer.
 var (
-	ErrByPassServiceResponse = errors.New("error of getting the response from by-pass-service")
-	ErrServiceResponse       = errors.New("error of getting the response")
-	ErrChromeDriver          = errors.New("error of the chrome driver's interaction")
-	ErrBufferReading         = errors.New("error of reading the data")
-	ErrConnectionClosed      = errors.New("the client has closed the connection")
-	ErrJSONResponseParsing   = errors.New("error of parsing the json-data")
+	ErrByPassServiceResponse = services.ErrByPassServiceResponse
+	ErrByPassBadRequest      = services.ErrByPassBadRequest
+	ErrByPassVersion         = services.ErrByPassVersion
+	ErrByPassUpstream        = services.ErrByPassUpstream
+	ErrByPassInternal        = services.ErrByPassInternal
+	ErrServiceResponse       = services.ErrServiceResponse
+	ErrServiceBlocked        = services.ErrServiceBlocked
+	ErrServiceThrottling     = services.ErrServiceThrottling
+	ErrChromeDriver          = services.ErrChromeDriver
+	ErrChromePullExhausted   = services.ErrChromePullExhausted
+	ErrChromePullClosed      = services.ErrChromePullClosed
+	ErrBufferReading         = services.ErrBufferReading
+	ErrConnectionClosed      = services.ErrConnectionClosed
+	ErrJSONResponseParsing   = services.ErrJSONResponseParsing
+	ErrEmptySample           = services.ErrEmptySample
+	ErrSchemaDrift           = services.ErrSchemaDrift
+	ErrUnsupportedRequest    = services.ErrUnsupportedRequest
 )
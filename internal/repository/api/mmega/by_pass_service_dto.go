@@ -1,6 +1,9 @@
 package mmega
 
-import "strings"
+import (
+	"encoding/json"
+	"strings"
+)
 
 type (
 	byPassPriceRangeFilter struct {
@@ -8,7 +11,9 @@ type (
 		PriceUp   string `json:"price_up"`
 	}
 
+	// byPassServiceRequest defines the body of the by-pass-service's request.
 	byPassServiceRequest struct {
+		Version            int                    `json:"version"`
 		Query              string                 `json:"query"`
 		Sample             string                 `json:"sample"`
 		Sort               string                 `json:"sort"`
@@ -16,10 +21,25 @@ type (
 		FlagPriceFilterSet bool                   `json:"is_price_filter_set"`
 		PriceRange         byPassPriceRangeFilter `json:"price_filter"`
 	}
+
+	// byPassServiceError defines the error's envelope of the by-pass-service's response.
+	byPassServiceError struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	}
+
+	// byPassServiceResponse defines the by-pass-service's response: it contains either the market's
+	// response in the data or the error.
+	byPassServiceResponse struct {
+		Version int                 `json:"version"`
+		Data    json.RawMessage     `json:"data"`
+		Error   *byPassServiceError `json:"error"`
+	}
 )
 
 func newByPassServiceRequest(query, sample, sort string, filters map[string]string) byPassServiceRequest {
 	byPassRequest := byPassServiceRequest{
+		Version:          byPassProtocolVersion,
 		Query:            query,
 		Sample:           sample,
 		Sort:             sort,
@@ -6,37 +6,58 @@ import (
 	"encoding/json"
 	"fmt"
 	"log/slog"
-	"math"
 	"net/http"
 
-	"github.com/labstack/echo/v4"
-
 	"github.com/MaKcm14/price-service/internal/entities/dto"
 	"github.com/MaKcm14/price-service/internal/repository/api"
 	"github.com/MaKcm14/price-service/pkg/entities"
 )
 
+// Opt defines the optional settings of the MegaMarketAPI.
+type Opt func(*MegaMarketAPI)
+
+// WithMode sets the way of the interaction with the MegaMarket.
+func WithMode(mode Mode) Opt {
+	return func(m *MegaMarketAPI) {
+		if mode == NativeMode || mode == ByPassMode {
+			m.mode = mode
+		}
+	}
+}
+
 type MegaMarketAPI struct {
 	logger       *slog.Logger
 	ctx          context.Context
 	parser       megaMarketParser
 	view         megaMarketViewer
 	byPassSocket string
+	mode         Mode
+	native       nativeClient
+	drift        *api.DriftDetector
 }
 
-func NewMegaMarketAPI(ctx context.Context, log *slog.Logger, socket string) MegaMarketAPI {
-	return MegaMarketAPI{
+func NewMegaMarketAPI(ctx context.Context, log *slog.Logger, socket string, opts ...Opt) MegaMarketAPI {
+	mmApi := MegaMarketAPI{
 		logger: log,
 		ctx:    ctx,
 		parser: megaMarketParser{
 			logger: log,
 		},
 		byPassSocket: socket,
+		mode:         ByPassMode,
+		native:       newNativeClient(log),
+		drift:        api.NewDriftDetector(log, entities.MegaMarket.String(), api.DriftThreshold),
 	}
+
+	for _, opt := range opts {
+		opt(&mmApi)
+	}
+
+	return mmApi
 }
 
-// getByPassProducts gets the products from by-pass-service.
-func (m MegaMarketAPI) getByPassProducts(ctx echo.Context, request dto.ProductRequest, filters []string) (*http.Response, error) {
+// getByPassProducts gets the market's raw json-response with the products from by-pass-service.
+func (m MegaMarketAPI) getByPassProducts(ctx context.Context, request dto.ProductRequest, filters []string) ([]byte, error) {
 	const serviceType = "megamarket.service.by-pass-interaction"
 
 	byPassRequest := newByPassServiceRequest(
@@ -45,32 +66,102 @@ func (m MegaMarketAPI) getByPassProducts(ctx echo.Context, request dto.ProductRe
 		m.view.getSortParamURLView(string(request.Sort)),
 		m.view.converter.GetFilters(filters),
 	)
+	byPassRequest.FlagShowNotAvail = request.Availability.ShowNotAvailable()
+
 	requestBody, _ := json.Marshal(byPassRequest)
 
-	if !request.Async && api.IsConnectionClosed(ctx) {
+	if api.IsConnectionClosed(ctx) {
 		m.logger.Warn(fmt.Sprintf("error of processing the %v: %v", serviceType, api.ErrConnectionClosed))
 		return nil, fmt.Errorf("error of processing the %v: %w", serviceType, api.ErrConnectionClosed)
 	}
 
-	resp, err := http.Post(fmt.Sprintf("http://%s/mmarket", m.byPassSocket), "application/json", bytes.NewBuffer(requestBody))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost,
+		fmt.Sprintf("http://%s/mmarket", m.byPassSocket), bytes.NewBuffer(requestBody))
 
 	if err != nil {
 		m.logger.Warn(fmt.Sprintf("error of the %s: %v", serviceType, err))
 		return nil, fmt.Errorf("error of the %s: %w", serviceType, api.ErrByPassServiceResponse)
-	} else if resp.StatusCode > 299 {
-		errDescr := make(map[string]string)
+	}
+	req.Header.Set("Content-Type", "application/json")
 
-		json.Marshal(errDescr)
+	resp, err := http.DefaultClient.Do(req)
 
-		m.logger.Warn(fmt.Sprintf("error of the %s: %s", serviceType, errDescr["error"]))
+	if err != nil {
+		m.logger.Warn(fmt.Sprintf("error of the %s: %v", serviceType, err))
 		return nil, fmt.Errorf("error of the %s: %w", serviceType, api.ErrByPassServiceResponse)
 	}
+	defer resp.Body.Close()
+
+	respBody, err := api.ReadResponseBody(resp.Body, m.logger, serviceType)
+
+	if err != nil {
+		return nil, fmt.Errorf("error of the %s: %w: %w", serviceType, api.ErrByPassServiceResponse, err)
+	}
+
+	var byPassResp byPassServiceResponse
+
+	// the errors of the proxies or the outdated by-pass-service may be not the json-envelopes:
+	// they're decoded according to the response's status.
+	if err := json.Unmarshal(respBody, &byPassResp); err != nil && resp.StatusCode <= 299 {
+		m.logger.Warn(fmt.Sprintf("error of the %s: %v: %v", serviceType, api.ErrJSONResponseParsing, err))
+		return nil, fmt.Errorf("error of the %s: %w: %v", serviceType, api.ErrJSONResponseParsing, err)
+	}
+
+	if resp.StatusCode > 299 || byPassResp.Error != nil {
+		err := m.getByPassError(resp.StatusCode, byPassResp.Error)
+		m.logger.Warn(fmt.Sprintf("error of the %s: %v", serviceType, err))
+		return nil, fmt.Errorf("error of the %s: %w", serviceType, err)
+	}
+
+	if byPassResp.Version != byPassProtocolVersion {
+		m.logger.Warn(fmt.Sprintf("error of the %s: %v: version %d", serviceType, api.ErrByPassVersion, byPassResp.Version))
+		return nil, fmt.Errorf("error of the %s: %w: %w: version %d", serviceType, api.ErrByPassServiceResponse,
+			api.ErrByPassVersion, byPassResp.Version)
+	}
+
+	return byPassResp.Data, nil
+}
+
+// getByPassError returns the error of the by-pass-service according to its error's code or,
+// if the error's envelope couldn't be decoded, according to the response's status.
+func (m MegaMarketAPI) getByPassError(status int, errEnvelope *byPassServiceError) error {
+	code, message := "", http.StatusText(status)
+
+	if errEnvelope != nil {
+		code, message = errEnvelope.Code, errEnvelope.Message
+	}
+
+	if code == byPassUnsupportedVersionCode {
+		return fmt.Errorf("%w: %w: %s", api.ErrByPassServiceResponse, api.ErrByPassVersion, message)
+	} else if code == byPassBadRequestCode || (len(code) == 0 && status == http.StatusBadRequest) {
+		return fmt.Errorf("%w: %w: %s", api.ErrByPassServiceResponse, api.ErrByPassBadRequest, message)
+	} else if code == byPassUpstreamOverflowCode || (len(code) == 0 && status == http.StatusBadGateway) {
+		return fmt.Errorf("%w: %w: %w: %s", api.ErrByPassServiceResponse, api.ErrByPassUpstream,
+			api.ErrServiceBlocked, message)
+	} else if code == byPassInternalCode || len(code) == 0 {
+		return fmt.Errorf("%w: %w: %s", api.ErrByPassServiceResponse, api.ErrByPassInternal, message)
+	}
+	return fmt.Errorf("%w: %w: the unknown code %q: %s", api.ErrByPassServiceResponse, api.ErrByPassInternal, code, message)
+}
 
-	return resp, nil
+// getRawProducts gets the raw json-response with the products using the set mode of the interaction.
+func (m MegaMarketAPI) getRawProducts(ctx context.Context, request dto.ProductRequest, filters []string) ([]byte, error) {
+	if m.mode == NativeMode {
+		searchRequest := newNativeSearchRequest(
+			request.Query,
+			request.Sample,
+			m.view.getSortParamURLView(string(request.Sort)),
+			m.view.converter.GetFilters(filters),
+		)
+		searchRequest.FlagShowNotAvail = request.Availability.ShowNotAvailable()
+
+		return m.native.getProducts(ctx, searchRequest)
+	}
+	return m.getByPassProducts(ctx, request, filters)
 }
 
 // getProducts is the main function of getting the products from the MegaMarket-API calls.
-func (m MegaMarketAPI) getProducts(ctx echo.Context, request dto.ProductRequest, filters ...string) (entities.ProductSample, error) {
+func (m MegaMarketAPI) getProducts(ctx context.Context, request dto.ProductRequest, filters ...string) (entities.ProductSample, error) {
 	const serviceType = "megamarket.service.main-products-getter"
 
 	respByPassProds := struct {
@@ -80,20 +171,12 @@ func (m MegaMarketAPI) getProducts(ctx echo.Context, request dto.ProductRequest,
 	}
 	products := make([]entities.Product, 0, 50)
 
-	resp, err := m.getByPassProducts(ctx, request, filters)
+	jsonProducts, err := m.getRawProducts(ctx, request, filters)
 
 	if err != nil {
 		m.logger.Warn(fmt.Sprintf("error of the %v: %v", serviceType, err))
 		return entities.ProductSample{}, err
 	}
-	defer resp.Body.Close()
-
-	jsonProducts, err := api.ReadResponseBody(resp.Body, m.logger, serviceType)
-
-	if err != nil {
-		return entities.ProductSample{}, fmt.Errorf("error of the %v: error of the reading response body: %v",
-			serviceType, err)
-	}
 
 	err = json.Unmarshal(jsonProducts, &respByPassProds)
 
@@ -102,14 +185,14 @@ func (m MegaMarketAPI) getProducts(ctx echo.Context, request dto.ProductRequest,
 		return entities.ProductSample{}, fmt.Errorf("error of the %v: %w: %v", serviceType, api.ErrJSONResponseParsing, err)
 	}
 
-	amount := len(respByPassProds.Items)
-
-	if request.Amount == "min" {
-		amount = int(math.Min(float64(minValue), float64(amount)))
+	if err := m.drift.Check(m.parser.getDriftReport(respByPassProds.Items)); err != nil {
+		return entities.ProductSample{}, fmt.Errorf("error of the %v: %w", serviceType, err)
 	}
 
-	for i := 0; i != amount; i++ {
-		if respByPassProds.Items[i].FinalPrice == 0 {
+	for i := 0; i != len(respByPassProds.Items); i++ {
+		availability, quantity, delivery := m.parser.getStock(respByPassProds.Items[i])
+
+		if respByPassProds.Items[i].FinalPrice == 0 || !request.Availability.Allows(availability) {
 			continue
 		}
 		products = append(products, entities.Product{
@@ -120,7 +203,10 @@ func (m MegaMarketAPI) getProducts(ctx echo.Context, request dto.ProductRequest,
 				URL:       respByPassProds.Items[i].Goods.URL,
 				ImageLink: respByPassProds.Items[i].Goods.TitleImageLink,
 			},
-			Supplier: respByPassProds.Items[i].Offer.MerchantName,
+			Supplier:     respByPassProds.Items[i].Offer.MerchantName,
+			Availability: availability,
+			Quantity:     quantity,
+			Delivery:     delivery,
 		})
 	}
 
@@ -128,24 +214,36 @@ func (m MegaMarketAPI) getProducts(ctx echo.Context, request dto.ProductRequest,
 }
 
 // GetProducts gets the products without any filters.
-func (m MegaMarketAPI) GetProducts(ctx echo.Context, request dto.ProductRequest) (entities.ProductSample, error) {
+func (m MegaMarketAPI) GetProducts(ctx context.Context, request dto.ProductRequest) (entities.ProductSample, error) {
 	return m.getProducts(ctx, request, sortID, m.view.getSortParamURLView(string(request.Sort)))
 }
 
 // GetProductsByPriceRange gets the products with filter by price range.
-func (m MegaMarketAPI) GetProductsWithPriceRange(ctx echo.Context, request dto.ProductRequest) (entities.ProductSample, error) {
+func (m MegaMarketAPI) GetProductsWithPriceRange(ctx context.Context, request dto.ProductRequest) (entities.ProductSample, error) {
 	return m.getProducts(ctx, request, sortID, m.view.getSortParamURLView(string(request.Sort)),
 		priceRangeID, fmt.Sprintf("%d %d", request.PriceRange.PriceDown, request.PriceRange.PriceUp))
 }
 
 // GetProductsByExactPrice gets the products with filter by price
-// in range [exactPrice, exactPrice + 10% off exactPrice].
-func (m MegaMarketAPI) GetProductsWithExactPrice(ctx echo.Context, request dto.ProductRequest) (entities.ProductSample, error) {
+// in the tolerance window around the exact price.
+func (m MegaMarketAPI) GetProductsWithExactPrice(ctx context.Context, request dto.ProductRequest) (entities.ProductSample, error) {
+	priceRange := request.GetExactPriceRange()
+
 	return m.getProducts(ctx, request, sortID, m.view.getSortParamURLView(string(request.Sort)),
-		priceRangeID, fmt.Sprintf("%d %d", request.ExactPrice, int(float32(request.ExactPrice)*1.1)))
+		priceRangeID, fmt.Sprintf("%d %d", priceRange.PriceDown, priceRange.PriceUp))
 }
 
 // GetProductsByBestPrice gets the products with filter by min price.
-func (m MegaMarketAPI) GetProductsWithBestPrice(ctx echo.Context, request dto.ProductRequest) (entities.ProductSample, error) {
+func (m MegaMarketAPI) GetProductsWithBestPrice(ctx context.Context, request dto.ProductRequest) (entities.ProductSample, error) {
+	request.Sort = dto.PriceUpSort
 	return m.getProducts(ctx, request, sortID, m.view.getSortParamURLView(string(request.Sort)))
 }
+
+// GetPagesConcurrency returns the amount of the pages that can be got simultaneously:
+// the pages are got through the by-pass-service one by one.
+func (m MegaMarketAPI) GetPagesConcurrency(request dto.ProductRequest) int {
+	if m.mode == NativeMode {
+		return pagesConcurrency
+	}
+	return 1
+}
@@ -8,29 +8,71 @@ import (
 
 	"github.com/MaKcm14/price-service/internal/entities/dto"
 	"github.com/MaKcm14/price-service/internal/repository/api"
+	"github.com/MaKcm14/price-service/pkg/entities"
 )
 
 // URL paths' consts.
 const (
 	megaMarketOrigin      = "https://megamarket.ru"
 	megaMarketOpenApiPath = "/catalog/page-"
+	megaMarketSearchPath  = "/api/mobile/v1/catalogService/catalog/search"
 	priceRangeKey         = "88C83F68482F447C9F4E401955196697"
 )
 
-// URL query params' consts.
+// The by-pass-service's protocol's consts.
 const (
-	priceRangeID = "filters"
-	sortID       = "sort"
+	byPassProtocolVersion = 1
+
+	byPassBadRequestCode         = "bad_request"
+	byPassUnsupportedVersionCode = "unsupported_version"
+	byPassUpstreamOverflowCode   = "upstream_overflow"
+	byPassInternalCode           = "internal"
+)
+
+// The native catalog search's consts.
+const (
+	searchRequestVersion     = 12
+	collectionRequestVersion = 10
+	searchPageLimit          = 44
+	priceDownFilterType      = 1
+	priceUpFilterType        = 2
+	serviceLimitCode         = 7
+	nativeUserAgent          = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/123.0.0.0 Safari/537.36"
+)
+
+const (
+	// pagesConcurrency is the max amount of the pages that can be got simultaneously in the native mode:
+	// the by-pass-service limits its requests so its pages are got one by one.
+	pagesConcurrency = 3
 )
 
+// Mode defines the way of the interaction with the MegaMarket.
+type Mode string
+
+const (
+	// NativeMode defines the direct requests to the MegaMarket's catalog API.
+	NativeMode Mode = "native"
+
+	// ByPassMode defines the requests through the by-pass-service.
+	ByPassMode Mode = "by-pass"
+)
+
+// URL query params' consts.
 const (
-	// minValue is the min amount of products that can be got from the service in one sample.
-	minValue = 15
+	priceRangeID = "filters"
+	sortID       = "sort"
 )
 
 type (
 	megaMarketProductOffer struct {
-		MerchantName string `json:"merchantName"`
+		MerchantName          string               `json:"merchantName"`
+		AvailableQuantity     *int                 `json:"availableQuantity"`
+		DeliveryPossibilities []megaMarketDelivery `json:"deliveryPossibilities"`
+	}
+
+	megaMarketDelivery struct {
+		Date        string `json:"displayDeliveryDate"`
+		FlagDefault bool   `json:"isDefault"`
 	}
 
 	megaMarketProductDecription struct {
@@ -45,6 +87,9 @@ type (
 		Price      int                         `json:"price"`
 		FinalPrice int                         `json:"finalPrice"`
 		Offer      megaMarketProductOffer      `json:"favoriteOffer"`
+
+		FlagAvailable *bool `json:"isAvailable"`
+		FlagPreorder  bool  `json:"isPreorder"`
 	}
 
 	// megaMarketViewer defines the specific view of the filters and url for MegaMarket.
@@ -108,3 +153,49 @@ func (v megaMarketViewer) getSortParamURLView(sort string) string {
 	}
 	return "0"
 }
+
+// getStock returns the product's availability, its quantity and the estimate of its delivery:
+// the default delivery's date or the first one. The availability is unknown if the market hasn't set it.
+func (p megaMarketParser) getStock(item megaMarketProduct) (entities.Availability, *int, *entities.ProductDelivery) {
+	var delivery *entities.ProductDelivery
+
+	for _, possibility := range item.Offer.DeliveryPossibilities {
+		if len(possibility.Date) != 0 && (delivery == nil || possibility.FlagDefault) {
+			delivery = &entities.ProductDelivery{Date: possibility.Date}
+		}
+	}
+
+	if item.FlagPreorder {
+		return entities.Preorder, item.Offer.AvailableQuantity, delivery
+	} else if item.FlagAvailable == nil {
+		return entities.UnknownAvailability, item.Offer.AvailableQuantity, delivery
+	} else if !*item.FlagAvailable {
+		return entities.OutOfStock, item.Offer.AvailableQuantity, delivery
+	}
+	return entities.InStock, item.Offer.AvailableQuantity, delivery
+}
+
+// getDriftReport returns the report of the response's products with the missing critical fields.
+func (p megaMarketParser) getDriftReport(items []megaMarketProduct) api.DriftReport {
+	report := api.NewDriftReport()
+
+	for _, item := range items {
+		missing := make([]string, 0, 3)
+
+		if len(item.Goods.Title) == 0 {
+			missing = append(missing, "title")
+		}
+
+		if len(item.Goods.URL) == 0 {
+			missing = append(missing, "webUrl")
+		}
+
+		if item.FinalPrice == 0 {
+			missing = append(missing, "finalPrice")
+		}
+
+		report.Add(missing...)
+	}
+
+	return report
+}
@@ -1,10 +1,20 @@
 package mmega
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
 	"testing"
 
 	"github.com/MaKcm14/price-service/internal/entities/dto"
+	"github.com/MaKcm14/price-service/internal/repository/api"
+	"github.com/MaKcm14/price-service/internal/services"
+	"github.com/MaKcm14/price-service/pkg/entities"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -33,3 +43,283 @@ func TestGetOpenApiURLPositiveCases(t *testing.T) {
 			url)
 	})
 }
+
+func TestGetDriftReportCases(t *testing.T) {
+	var testParserObj = megaMarketParser{
+		logger: slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError + 1})),
+	}
+
+	tests := []struct {
+		name    string
+		fixture string
+		wantErr bool
+	}{
+		{"Positive Case: the actual schema", "../../../../test/repository/api/mmega/positive_case_items.json", false},
+		{"Negative Case: the drifted schema", "../../../../test/repository/api/mmega/drift_case_items.json", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var response struct {
+				Items []megaMarketProduct `json:"items"`
+			}
+
+			buf, err := os.ReadFile(tt.fixture)
+
+			if err != nil || json.Unmarshal(buf, &response) != nil {
+				t.Fatal("error of the test configuration: couldn't load the test fixture")
+			}
+
+			detector := api.NewDriftDetector(testParserObj.logger, entities.MegaMarket.String(), api.DriftThreshold)
+			err = detector.Check(testParserObj.getDriftReport(response.Items))
+
+			if tt.wantErr {
+				assert.ErrorIs(t, err, api.ErrSchemaDrift)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestNewNativeSearchRequestCases(t *testing.T) {
+	t.Run("Positive Case: the request without price-range", func(t *testing.T) {
+		searchRequest := newNativeSearchRequest("iphone 11", 3, "1", map[string]string{sortID: "1"})
+
+		assert.Equal(t, 88, searchRequest.Offset)
+		assert.Equal(t, 1, searchRequest.Sorting)
+		assert.Equal(t, "iphone 11", searchRequest.SearchText)
+		assert.Empty(t, searchRequest.SelectedFilters)
+	})
+
+	t.Run("Positive Case: the request with price-range", func(t *testing.T) {
+		searchRequest := newNativeSearchRequest("iphone 11", 1, "0", map[string]string{priceRangeID: "100 1000"})
+
+		assert.Equal(t, []nativeFilter{
+			{FilterID: priceRangeKey, Type: priceDownFilterType, Value: "100"},
+			{FilterID: priceRangeKey, Type: priceUpFilterType, Value: "1000"},
+		}, searchRequest.SelectedFilters)
+	})
+
+	t.Run("Positive Case: the request of the collection", func(t *testing.T) {
+		body, _ := json.Marshal(newNativeSearchRequest("iphone 11", 1, "0", nil).withCollection("123"))
+		fields := make(map[string]any)
+		json.Unmarshal(body, &fields)
+
+		assert.NotContains(t, fields, "searchText")
+		assert.Equal(t, "123", fields["collectionId"])
+		assert.Equal(t, float64(collectionRequestVersion), fields["requestVersion"])
+		assert.Nil(t, fields["ageMore18"])
+	})
+}
+
+// newTestNativeClient returns the native client that sends the requests to the server
+// answering with the set responses in turn: the last response is repeated.
+func newTestNativeClient(requests *[]map[string]any, statuses []int, bodies []string) (nativeClient, *httptest.Server) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fields := make(map[string]any)
+		json.NewDecoder(r.Body).Decode(&fields)
+		*requests = append(*requests, fields)
+
+		i := min(len(*requests)-1, len(statuses)-1)
+
+		w.WriteHeader(statuses[i])
+		w.Write([]byte(bodies[i]))
+	}))
+
+	client := newNativeClient(slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError + 1})))
+	client.origin = server.URL
+
+	return client, server
+}
+
+func TestNativeClientPositiveCases(t *testing.T) {
+	t.Run("Positive Case: the products are got at once", func(t *testing.T) {
+		var requests []map[string]any
+		client, server := newTestNativeClient(&requests, []int{http.StatusOK}, []string{`{"items":[{"price":1}]}`})
+		defer server.Close()
+
+		body, err := client.getProducts(context.Background(), newNativeSearchRequest("iphone", 1, "0", nil))
+
+		assert.NoError(t, err)
+		assert.Equal(t, `{"items":[{"price":1}]}`, string(body))
+		assert.Len(t, requests, 1)
+	})
+
+	t.Run("Positive Case: the products are got from the assumed collection", func(t *testing.T) {
+		var requests []map[string]any
+		client, server := newTestNativeClient(&requests, []int{http.StatusOK, http.StatusOK},
+			[]string{`{"items":[],"processor":{"collectionId":"42"}}`, `{"items":[{"price":1}]}`})
+		defer server.Close()
+
+		body, err := client.getProducts(context.Background(), newNativeSearchRequest("iphone", 1, "0", nil))
+
+		if assert.NoError(t, err) && assert.Len(t, requests, 2) {
+			assert.Equal(t, `{"items":[{"price":1}]}`, string(body))
+			assert.Equal(t, "42", requests[1]["selectedAssumedCollectionId"])
+			assert.NotContains(t, requests[1], "searchText")
+		}
+	})
+}
+
+func TestNativeClientNegativeCases(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		body    string
+		wantErr error
+	}{
+		{"Negative Case: the service's limit is over", http.StatusOK, `{"code":7}`, api.ErrServiceThrottling},
+		{"Negative Case: the unsuccessful response", http.StatusOK, `{"success":false}`, api.ErrServiceResponse},
+		{"Negative Case: the blocking", http.StatusForbidden, `<html></html>`, api.ErrServiceBlocked},
+		{"Negative Case: the malformed json", http.StatusOK, `{"items":`, api.ErrJSONResponseParsing},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests []map[string]any
+			client, server := newTestNativeClient(&requests, []int{tt.status}, []string{tt.body})
+			defer server.Close()
+
+			body, err := client.getProducts(context.Background(), newNativeSearchRequest("iphone", 1, "0", nil))
+
+			assert.ErrorIs(t, err, tt.wantErr)
+			assert.Nil(t, body)
+		})
+	}
+}
+
+// newTestByPassAPI returns the MegaMarketAPI that interacts with the by-pass-service
+// answering with the set response.
+func newTestByPassAPI(status int, body string) (MegaMarketAPI, *httptest.Server) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+		w.Write([]byte(body))
+	}))
+
+	return NewMegaMarketAPI(context.Background(),
+		slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError + 1})),
+		strings.TrimPrefix(server.URL, "http://")), server
+}
+
+func TestGetPagesConcurrencyCases(t *testing.T) {
+	tests := []struct {
+		name string
+		mode Mode
+		want int
+	}{
+		{"Positive Case: the native pages are got simultaneously", NativeMode, pagesConcurrency},
+		{"Extreme Case: the by-pass pages are got one by one", ByPassMode, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testApiObj := NewMegaMarketAPI(context.Background(),
+				slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError + 1})), "", WithMode(tt.mode))
+
+			assert.Equal(t, tt.want, testApiObj.GetPagesConcurrency(dto.ProductRequest{}))
+		})
+	}
+}
+
+func TestGetByPassProductsPositiveCase(t *testing.T) {
+	testApiObj, server := newTestByPassAPI(http.StatusOK, `{"version":1,"data":{"items":[{"price":1}]}}`)
+	defer server.Close()
+
+	body, err := testApiObj.getByPassProducts(context.Background(), dto.ProductRequest{Query: "iphone", Sample: 1}, nil)
+
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"items":[{"price":1}]}`, string(body))
+}
+
+func TestGetByPassProductsNegativeCases(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   int
+		body     string
+		wantErr  error
+		wantCode string
+	}{
+		{"Negative Case: the bad request", http.StatusBadRequest,
+			`{"version":1,"error":{"code":"bad_request","message":"error of the request's structure"}}`,
+			api.ErrByPassBadRequest, "by_pass_bad_request"},
+		{"Negative Case: the unsupported version", http.StatusBadRequest,
+			`{"version":1,"error":{"code":"unsupported_version","message":"use 1"}}`,
+			api.ErrByPassVersion, "by_pass_version"},
+		{"Negative Case: the upstream's overflow", http.StatusBadGateway,
+			`{"version":1,"error":{"code":"upstream_overflow","message":"the limit of the service is over"}}`,
+			api.ErrServiceBlocked, "by_pass_upstream_overflow"},
+		{"Negative Case: the internal error", http.StatusInternalServerError,
+			`{"version":1,"error":{"code":"internal","message":"error of the service interaction"}}`,
+			api.ErrByPassInternal, "by_pass_internal"},
+		{"Negative Case: the unknown error's code", http.StatusInternalServerError,
+			`{"version":1,"error":{"code":"maintenance","message":"the service is stopped"}}`,
+			api.ErrByPassInternal, "by_pass_internal"},
+		{"Negative Case: the outdated error's view", http.StatusBadGateway,
+			`{'error': 'the limit of the service is over'}`,
+			api.ErrByPassUpstream, "by_pass_upstream_overflow"},
+		{"Negative Case: the outdated response's view", http.StatusOK,
+			`{"items":[]}`,
+			api.ErrByPassVersion, "by_pass_version"},
+		{"Negative Case: the malformed json", http.StatusOK,
+			`{"version":1,"data":`,
+			api.ErrJSONResponseParsing, "json_response_parsing"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testApiObj, server := newTestByPassAPI(tt.status, tt.body)
+			defer server.Close()
+
+			body, err := testApiObj.getByPassProducts(context.Background(), dto.ProductRequest{Query: "iphone", Sample: 1}, nil)
+
+			assert.ErrorIs(t, err, tt.wantErr)
+			assert.Equal(t, tt.wantCode, services.ErrorCode(err))
+			assert.Nil(t, body)
+		})
+	}
+}
+
+func TestGetByPassErrorCases(t *testing.T) {
+	t.Run("Negative Case: the upstream's overflow is the only blocking", func(t *testing.T) {
+		err := MegaMarketAPI{}.getByPassError(http.StatusBadGateway, &byPassServiceError{Code: byPassUpstreamOverflowCode})
+
+		assert.ErrorIs(t, err, api.ErrServiceBlocked)
+		assert.NotErrorIs(t, err, api.ErrServiceThrottling)
+	})
+}
+
+func TestGetStockCases(t *testing.T) {
+	var testParserObj = megaMarketParser{
+		logger: slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError + 1})),
+	}
+
+	tests := []struct {
+		name         string
+		item         string
+		availability entities.Availability
+		delivery     *entities.ProductDelivery
+	}{
+		{"Positive Case: the product is in stock", `{"isAvailable": true, "favoriteOffer": {"availableQuantity": 4,
+			"deliveryPossibilities": [{"displayDeliveryDate": "tomorrow"}, {"displayDeliveryDate": "today", "isDefault": true}]}}`,
+			entities.InStock, &entities.ProductDelivery{Date: "today"}},
+		{"Positive Case: the product is preordered", `{"isAvailable": false, "isPreorder": true}`, entities.Preorder, nil},
+		{"Negative Case: the product is out of stock", `{"isAvailable": false}`, entities.OutOfStock, nil},
+		{"Extreme Case: the availability isn't set", `{}`, entities.UnknownAvailability, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var item megaMarketProduct
+
+			if err := json.Unmarshal([]byte(tt.item), &item); err != nil {
+				t.Fatal("error of the test configuration: couldn't unmarshal the item")
+			}
+
+			availability, _, delivery := testParserObj.getStock(item)
+
+			assert.Equal(t, tt.availability, availability)
+			assert.Equal(t, tt.delivery, delivery)
+		})
+	}
+}
@@ -0,0 +1,125 @@
+package mmega
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/MaKcm14/price-service/internal/repository/api"
+)
+
+// nativeClient defines the direct interaction with the MegaMarket's catalog API
+// that replaces the by-pass-service.
+type nativeClient struct {
+	logger *slog.Logger
+	client *http.Client
+	origin string
+}
+
+func newNativeClient(log *slog.Logger) nativeClient {
+	return nativeClient{
+		logger: log,
+		client: &http.Client{},
+		origin: megaMarketOrigin,
+	}
+}
+
+// search makes the only catalog search request and checks the market's response.
+func (c nativeClient) search(ctx context.Context, searchRequest nativeSearchRequest) ([]byte, nativeSearchResponse, error) {
+	const serviceType = "megamarket.service.native-search"
+
+	var searchResp nativeSearchResponse
+
+	requestBody, err := json.Marshal(searchRequest)
+
+	if err != nil {
+		return nil, searchResp, fmt.Errorf("error of the %s: %w: %v", serviceType, api.ErrServiceResponse, err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.origin+megaMarketSearchPath, bytes.NewBuffer(requestBody))
+
+	if err != nil {
+		return nil, searchResp, fmt.Errorf("error of the %s: %w: %v", serviceType, api.ErrServiceResponse, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Origin", megaMarketOrigin)
+	req.Header.Set("User-Agent", nativeUserAgent)
+
+	resp, err := c.client.Do(req)
+
+	if err != nil {
+		if api.IsConnectionClosed(ctx) {
+			return nil, searchResp, fmt.Errorf("error of the %s: %w: %v", serviceType, api.ErrConnectionClosed, err)
+		}
+		return nil, searchResp, fmt.Errorf("error of the %s: %w: %v", serviceType, api.ErrServiceResponse, err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := api.ReadResponseBody(resp.Body, c.logger, serviceType)
+
+	if err != nil {
+		return nil, searchResp, fmt.Errorf("error of the %s: %w", serviceType, err)
+	}
+
+	if err := json.Unmarshal(respBody, &searchResp); err != nil {
+		if resp.StatusCode > 299 {
+			return nil, searchResp, c.statusError(serviceType, resp.StatusCode)
+		}
+		return nil, searchResp, fmt.Errorf("error of the %s: %w: %v", serviceType, api.ErrJSONResponseParsing, err)
+	}
+
+	if searchResp.Code == serviceLimitCode {
+		return nil, searchResp, fmt.Errorf("error of the %s: %w: %w: %w", serviceType, api.ErrServiceResponse,
+			api.ErrServiceBlocked, api.ErrServiceThrottling)
+	} else if searchResp.Success != nil && !*searchResp.Success {
+		return nil, searchResp, fmt.Errorf("error of the %s: %w: the market has returned the unsuccessful response",
+			serviceType, api.ErrServiceResponse)
+	} else if resp.StatusCode > 299 {
+		return nil, searchResp, c.statusError(serviceType, resp.StatusCode)
+	}
+
+	return respBody, searchResp, nil
+}
+
+// statusError returns the error of the market's response with the unsuccessful status.
+func (c nativeClient) statusError(serviceType string, status int) error {
+	if status == http.StatusTooManyRequests {
+		return fmt.Errorf("error of the %s: %w: %w: %w: status %d", serviceType, api.ErrServiceResponse,
+			api.ErrServiceBlocked, api.ErrServiceThrottling, status)
+	} else if status == http.StatusForbidden {
+		return fmt.Errorf("error of the %s: %w: %w: status %d", serviceType, api.ErrServiceResponse,
+			api.ErrServiceBlocked, status)
+	}
+	return fmt.Errorf("error of the %s: %w: status %d", serviceType, api.ErrServiceResponse, status)
+}
+
+// getProducts gets the raw json-response with the products from the MegaMarket's catalog API.
+// If the search text was assumed to the collection and the response hasn't got any products
+// the products are requested from this collection.
+func (c nativeClient) getProducts(ctx context.Context, searchRequest nativeSearchRequest) ([]byte, error) {
+	const serviceType = "megamarket.service.native-products-getter"
+
+	respBody, searchResp, err := c.search(ctx, searchRequest)
+
+	if err != nil {
+		c.logger.Warn(fmt.Sprintf("error of the %s: %v", serviceType, err))
+		return nil, err
+	}
+
+	if searchResp.Items == nil || len(searchResp.Items) != 0 || len(searchResp.Processor.CollectionID) == 0 {
+		return respBody, nil
+	}
+
+	respBody, _, err = c.search(ctx, searchRequest.withCollection(searchResp.Processor.CollectionID))
+
+	if err != nil {
+		c.logger.Warn(fmt.Sprintf("error of the %s: %v", serviceType, err))
+		return nil, err
+	}
+
+	return respBody, nil
+}
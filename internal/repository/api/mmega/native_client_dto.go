@@ -0,0 +1,109 @@
+package mmega
+
+import (
+	"strconv"
+	"strings"
+)
+
+// nativeAuth defines the client's description that is required by the MegaMarket's catalog API.
+var nativeAuth = nativeAuthBlock{
+	LocationID:  "50",
+	AppPlatform: "WEB",
+	AppVersion:  0,
+	Experiments: map[string]string{
+		"8": "2", "55": "2", "58": "2", "68": "1", "69": "2", "79": "3", "99": "1", "107": "2", "109": "2",
+		"119": "2", "120": "2", "121": "2", "122": "2", "132": "2", "144": "3", "154": "1", "173": "1",
+		"182": "1", "184": "3", "186": "2", "190": "2", "192": "2", "194": "3", "200": "2", "205": "2",
+		"209": "1", "218": "1", "243": "1", "249": "3", "645": "3", "646": "2", "775": "2", "777": "2",
+		"778": "2", "790": "3", "792": "3", "793": "3", "805": "2", "808": "3", "818": "2", "826": "2",
+		"828": "2", "837": "2", "842": "2", "844": "1", "845": "2", "852": "1", "889": "1", "893": "1",
+		"897": "1", "899": "1", "903": "1", "945": "1", "958": "2", "962": "2", "1054": "2", "5779": "2",
+		"20121": "1", "43568": "2", "67319": "2", "70070": "2", "80283": "1", "85160": "2", "91562": "3",
+	},
+	OS: "UNKNOWN_OS",
+}
+
+type (
+	nativeAuthBlock struct {
+		LocationID  string            `json:"locationId"`
+		AppPlatform string            `json:"appPlatform"`
+		AppVersion  int               `json:"appVersion"`
+		Experiments map[string]string `json:"experiments"`
+		OS          string            `json:"os"`
+	}
+
+	nativeFilter struct {
+		FilterID string `json:"filterId"`
+		Type     int    `json:"type"`
+		Value    string `json:"value"`
+	}
+
+	// nativeSearchRequest defines the body of the MegaMarket's catalog search request.
+	nativeSearchRequest struct {
+		RequestVersion              int             `json:"requestVersion"`
+		Merchant                    struct{}        `json:"merchant"`
+		Limit                       int             `json:"limit"`
+		Offset                      int             `json:"offset"`
+		FlagMultiCategorySearch     bool            `json:"isMultiCategorySearch"`
+		FlagSearchByOriginalQuery   bool            `json:"searchByOriginalQuery"`
+		SelectedSuggestParams       []string        `json:"selectedSuggestParams"`
+		ExpandedFiltersIDs          []string        `json:"expandedFiltersIds"`
+		Sorting                     int             `json:"sorting"`
+		AgeMore18                   *bool           `json:"ageMore18"`
+		FlagShowNotAvail            bool            `json:"showNotAvailable"`
+		SelectedFilters             []nativeFilter  `json:"selectedFilters"`
+		SearchText                  string          `json:"searchText,omitempty"`
+		CollectionID                string          `json:"collectionId,omitempty"`
+		SelectedAssumedCollectionID string          `json:"selectedAssumedCollectionId,omitempty"`
+		Auth                        nativeAuthBlock `json:"auth"`
+	}
+
+	// nativeSearchResponse defines the fields of the MegaMarket's catalog search response
+	// that are checked before the products are parsed.
+	nativeSearchResponse struct {
+		Success   *bool `json:"success"`
+		Code      int   `json:"code"`
+		Items     []any `json:"items"`
+		Processor struct {
+			CollectionID string `json:"collectionId"`
+		} `json:"processor"`
+	}
+)
+
+func newNativeSearchRequest(query string, sample int, sort string, filters map[string]string) nativeSearchRequest {
+	sorting, _ := strconv.Atoi(sort)
+
+	searchRequest := nativeSearchRequest{
+		RequestVersion:        searchRequestVersion,
+		Limit:                 searchPageLimit,
+		Offset:                (sample - 1) * searchPageLimit,
+		SelectedSuggestParams: []string{},
+		ExpandedFiltersIDs:    []string{},
+		Sorting:               sorting,
+		FlagShowNotAvail:      false,
+		SelectedFilters:       []nativeFilter{},
+		SearchText:            query,
+		Auth:                  nativeAuth,
+	}
+
+	if priceParam, flagExist := filters[priceRangeID]; flagExist {
+		prices := strings.Split(priceParam, " ")
+
+		searchRequest.SelectedFilters = []nativeFilter{
+			{FilterID: priceRangeKey, Type: priceDownFilterType, Value: prices[0]},
+			{FilterID: priceRangeKey, Type: priceUpFilterType, Value: prices[1]},
+		}
+	}
+
+	return searchRequest
+}
+
+// withCollection returns the request of the products from the collection the search text was assumed to.
+func (r nativeSearchRequest) withCollection(collectionID string) nativeSearchRequest {
+	r.RequestVersion = collectionRequestVersion
+	r.SearchText = ""
+	r.CollectionID = collectionID
+	r.SelectedAssumedCollectionID = collectionID
+
+	return r
+}
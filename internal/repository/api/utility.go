@@ -5,52 +5,32 @@ import (
 	"fmt"
 	"io"
 	"log/slog"
-
-	"github.com/chromedp/chromedp"
-	"github.com/labstack/echo/v4"
 )
 
-// ChromePull supports the safe opening and closing the connection with the instances of the browser.
-type ChromePull struct {
-	cancels []context.CancelFunc
-}
+// IsConnectionClosed checks is the context of the client's request is still alive:
+// it's done when the client has closed the connection or the request's deadline is exceeded.
+func IsConnectionClosed(ctx context.Context) bool {
+	select {
+	case <-ctx.Done():
+		return true
 
-func NewChromePull() *ChromePull {
-	return &ChromePull{
-		cancels: make([]context.CancelFunc, 0, 100),
+	default:
+		return false
 	}
 }
 
-// NewContext creates a new context.Context that connected with the allocated browser.
-func (c *ChromePull) NewContext() context.Context {
-	ctx, cancel := chromedp.NewExecAllocator(context.Background(),
-		append(chromedp.DefaultExecAllocatorOptions[:], chromedp.Flag("headless", false))...)
-	c.cancels = append(c.cancels, cancel)
-
-	ctx, cancel = chromedp.NewContext(ctx)
-	c.cancels = append(c.cancels, cancel)
-
-	return ctx
-}
+// BindContext returns the context derived from the driver's context that is cancelled
+// as soon as the request's context is done.
+func BindContext(driverCtx context.Context, ctx context.Context) (context.Context, context.CancelFunc) {
+	boundCtx, cancel := context.WithCancel(driverCtx)
+	stop := context.AfterFunc(ctx, cancel)
 
-// Close closes all the connections that were created with the NewContext.
-func (c ChromePull) Close() {
-	for _, cancel := range c.cancels {
+	return boundCtx, func() {
+		stop()
 		cancel()
 	}
 }
 
-// IsConnectionClosed checks is the connection with the client is still alive.
-func IsConnectionClosed(ctx echo.Context) bool {
-	select {
-	case <-ctx.Request().Context().Done():
-		return true
-
-	default:
-		return false
-	}
-}
-
 func ReadResponseBody(source io.Reader, logger *slog.Logger, serviceType string) ([]byte, error) {
 	respBody := make([]byte, 0, 100000)
 	for {
@@ -1,11 +1,19 @@
 package wildb
 
 import (
+	"context"
+	"encoding/json"
 	"log/slog"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/MaKcm14/price-service/internal/entities/dto"
+	"github.com/MaKcm14/price-service/internal/repository/api"
+	"github.com/MaKcm14/price-service/pkg/entities"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -27,9 +35,9 @@ func TestParseImageLinksPositiveCase(t *testing.T) {
 	resImageLinks := testParserObj.parseImageLinks(string(html))
 
 	assert.Equal(t, 2, len(resImageLinks))
-	assert.Equal(t, []string{
-		"https://basket-16.wbbasket.ru/vol2425/part242589/242589892/images/c516x688/1.webp",
-		"https://basket-16.wbbasket.ru/vol2611/part261162/261162615/images/c516x688/1.webp",
+	assert.Equal(t, map[int]string{
+		242589892: "https://basket-16.wbbasket.ru/vol2425/part242589/242589892/images/c516x688/1.webp",
+		261162615: "https://basket-16.wbbasket.ru/vol2611/part261162/261162615/images/c516x688/1.webp",
 	}, resImageLinks)
 }
 
@@ -52,7 +60,7 @@ func TestParseImageLinksExtremeCases(t *testing.T) {
 		resImageLinks := testParserObj.parseImageLinks(string(html))
 
 		assert.Equal(t, 0, len(resImageLinks))
-		assert.Equal(t, []string(nil), resImageLinks)
+		assert.Equal(t, map[int]string(nil), resImageLinks)
 	})
 
 	t.Run("Extreme Case: the wrong structure of the page was given", func(t *testing.T) {
@@ -73,7 +81,7 @@ func TestParseImageLinksExtremeCases(t *testing.T) {
 		resImageLinks := testParserObj.parseImageLinks(string(html))
 
 		assert.Equal(t, 0, len(resImageLinks))
-		assert.Equal(t, []string{}, resImageLinks)
+		assert.Equal(t, map[int]string{}, resImageLinks)
 	})
 }
 
@@ -124,3 +132,338 @@ func TestGetHiddenApiPathPositiveCases(t *testing.T) {
 		assert.Equal(t, "page=1&priceU=100000;500000&query=Test+Query&resultset=catalog&sort=popular&spp=30&suppressSpellcheck=false", url)
 	})
 }
+
+func TestPageLoaderCheckCases(t *testing.T) {
+	start := time.Now()
+
+	tests := []struct {
+		name   string
+		amount int
+		cards  []int
+		now    time.Time
+		want   entities.LoadingStopReason
+	}{
+		{"Positive Case: the enough cards were loaded", 10, []int{4, 8, 12}, start, entities.LoadingStopEnough},
+		{"Positive Case: the cards stopped growing", 50, []int{4, 8, 8, 8}, start, entities.LoadingStopStable},
+		{"Extreme Case: the deadline was reached", 50, []int{4}, start.Add(time.Hour), entities.LoadingStopDeadline},
+		{"Extreme Case: the page without cards", 10, []int{0, 0, 0}, start, entities.LoadingStopStable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var reason entities.LoadingStopReason
+			loader := newPageLoader(tt.amount, start.Add(time.Minute))
+
+			for i, cards := range tt.cards {
+				reason = loader.check(cards, tt.now)
+
+				if i != len(tt.cards)-1 {
+					assert.Empty(t, reason)
+				}
+			}
+
+			assert.Equal(t, tt.want, reason)
+			assert.Equal(t, tt.cards[len(tt.cards)-1], loader.getLoading(reason).Cards)
+		})
+	}
+}
+
+func TestGetCardsAmountCases(t *testing.T) {
+	tests := []struct {
+		name       string
+		amount     int
+		sampleSize int
+		want       int
+	}{
+		{"Positive Case: the requested amount is less than the page", 15, 100, 15},
+		{"Extreme Case: the requested amount is more than the page", 100, 40, 40},
+		{"Extreme Case: the amount isn't set", 0, 100, 100},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, getCardsAmount(dto.ProductRequest{Amount: tt.amount}, tt.sampleSize))
+		})
+	}
+}
+
+func TestParseImageLinksWithoutArticleIDCase(t *testing.T) {
+	var testParserObj = wildberriesParser{
+		logger: slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError})),
+	}
+
+	resImageLinks := testParserObj.parseImageLinks(`<div>` +
+		`<article class="product-card"><img class="j-thumbnail" src="https://test/1.webp"></article>` +
+		`<article data-nm-id="15" class="product-card"><img class="j-thumbnail" src="https://test/15.webp"></article>` +
+		`</div>`)
+
+	assert.Equal(t, map[int]string{15: "https://test/15.webp"}, resImageLinks)
+}
+
+func TestGetImageLinkCases(t *testing.T) {
+	tests := []struct {
+		name string
+		id   int
+		want string
+	}{
+		{"Positive Case: the middle basket", 242589892, "https://basket-16.wbbasket.ru/vol2425/part242589/242589892/images/c516x688/1.webp"},
+		{"Positive Case: the first basket", 14300001, "https://basket-01.wbbasket.ru/vol143/part14300/14300001/images/c516x688/1.webp"},
+		{"Extreme Case: the lower bound of the basket", 14400000, "https://basket-02.wbbasket.ru/vol144/part14400/14400000/images/c516x688/1.webp"},
+		{"Extreme Case: the last bounded basket", 456599999, "https://basket-25.wbbasket.ru/vol4565/part456599/456599999/images/c516x688/1.webp"},
+		{"Extreme Case: the volume over the last bound", 456600000, "https://basket-26.wbbasket.ru/vol4566/part456600/456600000/images/c516x688/1.webp"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var testViewObj = wildberriesViewer{}
+
+			assert.Equal(t, tt.want, testViewObj.getImageLink(tt.id))
+		})
+	}
+}
+
+// newTestSearchServer returns the server that answers with the set responses in turn:
+// the last response is repeated.
+func newTestSearchServer(calls *atomic.Int32, statuses []int, bodies []string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		i := min(int(calls.Add(1))-1, len(statuses)-1)
+
+		w.WriteHeader(statuses[i])
+		w.Write([]byte(bodies[i]))
+	}))
+}
+
+func newTestWildberriesAPI() WildberriesAPI {
+	testApiObj := NewWildberriesAPI(nil,
+		slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError})), 0)
+	testApiObj.backoff = time.Millisecond
+
+	return testApiObj
+}
+
+func TestGetProductSamplePositiveCases(t *testing.T) {
+	t.Run("Positive Case: the short sample is accepted at once", func(t *testing.T) {
+		var calls atomic.Int32
+		server := newTestSearchServer(&calls, []int{http.StatusOK},
+			[]string{`{"data":{"products":[{"id":1},{"id":2},{"id":3}]}}`})
+		defer server.Close()
+
+		products, err := newTestWildberriesAPI().getProductSample(context.Background(), server.URL)
+
+		assert.NoError(t, err)
+		assert.Len(t, products, 3)
+		assert.Equal(t, int32(1), calls.Load())
+	})
+
+	t.Run("Positive Case: the throttled request is repeated", func(t *testing.T) {
+		var calls atomic.Int32
+		server := newTestSearchServer(&calls, []int{http.StatusTooManyRequests, http.StatusOK},
+			[]string{``, `{"data":{"products":[{"id":1}]}}`})
+		defer server.Close()
+
+		products, err := newTestWildberriesAPI().getProductSample(context.Background(), server.URL)
+
+		assert.NoError(t, err)
+		assert.Len(t, products, 1)
+		assert.Equal(t, int32(2), calls.Load())
+	})
+}
+
+func TestGetProductSampleNegativeCases(t *testing.T) {
+	tests := []struct {
+		name      string
+		status    int
+		body      string
+		wantErr   error
+		wantCalls int
+	}{
+		{"Negative Case: the empty sample", http.StatusOK, `{"data":{"products":[]}}`, api.ErrEmptySample, 1},
+		{"Negative Case: the malformed json", http.StatusOK, `{"data":`, api.ErrJSONResponseParsing, 1},
+		{"Negative Case: the throttling", http.StatusTooManyRequests, ``, api.ErrServiceThrottling, maxAttempts},
+		{"Negative Case: the blocking", http.StatusForbidden, ``, api.ErrServiceBlocked, 1},
+		{"Negative Case: the upstream's failure", http.StatusBadGateway, ``, api.ErrServiceResponse, maxAttempts},
+		{"Negative Case: the wrong request", http.StatusNotFound, ``, api.ErrServiceResponse, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls atomic.Int32
+			server := newTestSearchServer(&calls, []int{tt.status}, []string{tt.body})
+			defer server.Close()
+
+			products, err := newTestWildberriesAPI().getProductSample(context.Background(), server.URL)
+
+			assert.ErrorIs(t, err, tt.wantErr)
+			assert.Nil(t, products)
+			assert.Equal(t, int32(tt.wantCalls), calls.Load())
+		})
+	}
+}
+
+func TestGetProductsUnsupportedCases(t *testing.T) {
+	t.Run("Negative Case: the preordered products aren't marked by the market", func(t *testing.T) {
+		request := dto.NewProductRequest()
+		request.Availability = dto.PreorderAvailability
+
+		_, err := newTestWildberriesAPI().GetProducts(context.Background(), request)
+
+		assert.ErrorIs(t, err, api.ErrUnsupportedRequest)
+	})
+}
+
+func TestValidateProductsCases(t *testing.T) {
+	var testParserObj = wildberriesParser{
+		logger: slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError})),
+	}
+	var sample []wildberriesProduct
+
+	err := json.Unmarshal([]byte(`[
+		{"id": 1, "name": "one size", "sizes": [{"price": {"basic": 20000, "total": 15000}}]},
+		{"id": 2, "name": "many sizes", "sizes": [
+			{"price": {"basic": 30000, "total": 25000}},
+			{"price": {"basic": 20000, "total": 0}},
+			{"price": {"basic": 20000, "total": 18000}}
+		]},
+		{"id": 3, "name": "no sizes", "sizes": []},
+		{"id": 4, "name": " "},
+		{"id": 5, "name": "zero price", "sizes": [{"price": {"basic": 0, "total": 0}}]},
+		{"id": 6, "name": "invalid price", "sizes": [{"price": {"basic": 100, "total": 200}}]}
+	]`), &sample)
+
+	if err != nil {
+		t.Fatal("error of the test configuration: couldn't parse the test sample")
+	}
+
+	products, rejected := testParserObj.validateProducts(sample)
+
+	if assert.Len(t, products, 2) {
+		assert.Equal(t, entities.Price{BasePrice: 200, DiscountPrice: 150, Discount: 25}, products[0].price)
+		assert.Equal(t, entities.Price{
+			BasePrice:     200,
+			DiscountPrice: 180,
+			Discount:      10,
+			Range:         &entities.PriceRange{Min: 180, Max: 250},
+		}, products[1].price)
+	}
+
+	assert.Equal(t, map[string]int{
+		rejectNoSizes:      1,
+		rejectNoName:       1,
+		rejectZeroPrice:    1,
+		rejectInvalidPrice: 1,
+	}, rejected)
+}
+
+func TestGetDriftReportCases(t *testing.T) {
+	var testParserObj = wildberriesParser{
+		logger: slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError + 1})),
+	}
+
+	tests := []struct {
+		name    string
+		fixture string
+		wantErr bool
+	}{
+		{"Positive Case: the actual schema", "../../../../test/repository/api/wildb/positive_case_search.json", false},
+		{"Negative Case: the drifted schema", "../../../../test/repository/api/wildb/drift_case_search.json", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var sample struct {
+				Data struct {
+					Products []wildberriesProduct `json:"products"`
+				} `json:"data"`
+			}
+
+			buf, err := os.ReadFile(tt.fixture)
+
+			if err != nil || json.Unmarshal(buf, &sample) != nil {
+				t.Fatal("error of the test configuration: couldn't load the test fixture")
+			}
+
+			detector := api.NewDriftDetector(testParserObj.logger, entities.Wildberries.String(), api.DriftThreshold)
+			err = detector.Check(testParserObj.getDriftReport(sample.Data.Products))
+
+			if tt.wantErr {
+				assert.ErrorIs(t, err, api.ErrSchemaDrift)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestGetStockCases(t *testing.T) {
+	var testParserObj = wildberriesParser{
+		logger: slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError})),
+	}
+
+	tests := []struct {
+		name         string
+		product      string
+		availability entities.Availability
+		quantity     *int
+		delivery     *entities.ProductDelivery
+	}{
+		{"Positive Case: the total quantity is set", `{"totalQuantity": 7, "time1": 4, "time2": 20}`,
+			entities.InStock, func() *int { q := 7; return &q }(), &entities.ProductDelivery{Hours: 24}},
+		{"Positive Case: the quantity is summed from the sizes' stocks", `{"sizes": [{"stocks": [{"qty": 2}, {"qty": 3}]}, {"stocks": []}]}`,
+			entities.InStock, func() *int { q := 5; return &q }(), nil},
+		{"Negative Case: the product is out of stock", `{"sizes": [{"stocks": []}]}`,
+			entities.OutOfStock, new(int), nil},
+		{"Extreme Case: the stock fields aren't set", `{"sizes": [{"price": {"basic": 100, "total": 90}}]}`,
+			entities.UnknownAvailability, nil, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var product wildberriesProduct
+
+			if err := json.Unmarshal([]byte(tt.product), &product); err != nil {
+				t.Fatal("error of the test configuration: couldn't unmarshal the product")
+			}
+
+			availability, quantity, delivery := testParserObj.getStock(product)
+
+			assert.Equal(t, tt.availability, availability)
+			assert.Equal(t, tt.quantity, quantity)
+			assert.Equal(t, tt.delivery, delivery)
+		})
+	}
+}
+
+func TestFilterAvailableCases(t *testing.T) {
+	var testParserObj = wildberriesParser{
+		logger: slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError})),
+	}
+	inStock, outOfStock := 3, 0
+
+	sample := []validProduct{
+		{wildberriesProduct: wildberriesProduct{ID: 1, TotalQuantity: &inStock}},
+		{wildberriesProduct: wildberriesProduct{ID: 2, TotalQuantity: &outOfStock}},
+		{wildberriesProduct: wildberriesProduct{ID: 3}},
+	}
+
+	tests := []struct {
+		name         string
+		availability dto.AvailabilityType
+		want         []int
+	}{
+		{"Positive Case: the products in stock", dto.InStockAvailability, []int{1, 3}},
+		{"Positive Case: all the products", dto.AllAvailability, []int{1, 2, 3}},
+		{"Extreme Case: the preordered products", dto.PreorderAvailability, []int{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ids := make([]int, 0, len(sample))
+
+			for _, product := range testParserObj.filterAvailable(sample, tt.availability) {
+				ids = append(ids, product.ID)
+			}
+
+			assert.Equal(t, tt.want, ids)
+		})
+	}
+}
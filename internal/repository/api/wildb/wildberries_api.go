@@ -3,6 +3,7 @@ package wildb
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
@@ -10,7 +11,6 @@ import (
 
 	"github.com/chromedp/chromedp"
 	"github.com/chromedp/chromedp/kb"
-	"github.com/labstack/echo/v4"
 
 	"github.com/MaKcm14/price-service/pkg/entities"
 
@@ -18,6 +18,18 @@ import (
 	"github.com/MaKcm14/price-service/internal/repository/api"
 )
 
+// Opt defines the optional settings of the WildberriesAPI.
+type Opt func(*WildberriesAPI)
+
+// WithImagesSource sets the source of the products' image links.
+func WithImagesSource(source ImagesSource) Opt {
+	return func(w *WildberriesAPI) {
+		if source == IDImages || source == BrowserImages {
+			w.images = source
+		}
+	}
+}
+
 // WildberriesAPI defines the rules of interaction with the wildberries service and
 // provides the interface of getting the products with set clients' filters.
 type WildberriesAPI struct {
@@ -25,180 +37,306 @@ type WildberriesAPI struct {
 	loadCoeff time.Duration
 	parser    wildberriesParser
 	view      wildberriesViewer
-	ctx       context.Context
+	tabs      api.TabsPull
+	images    ImagesSource
+	backoff   time.Duration
+	drift     *api.DriftDetector
 }
 
-func NewWildberriesAPI(ctx context.Context, log *slog.Logger, loadCoeff int) WildberriesAPI {
-	return WildberriesAPI{
+func NewWildberriesAPI(tabs api.TabsPull, log *slog.Logger, loadCoeff int, opts ...Opt) WildberriesAPI {
+	wbApi := WildberriesAPI{
 		logger:    log,
 		loadCoeff: time.Duration(loadCoeff) * time.Millisecond,
 		parser: wildberriesParser{
 			logger: log,
 		},
-		ctx: ctx,
+		tabs:    tabs,
+		images:  IDImages,
+		backoff: retryBackoff,
+		drift:   api.NewDriftDetector(log, entities.Wildberries.String(), api.DriftThreshold),
+	}
+
+	for _, opt := range opts {
+		opt(&wbApi)
+	}
+
+	return wbApi
+}
+
+// getCardsAmount returns the amount of the products' cards that must be loaded on the page:
+// it's the requested amount of the products if it's set but not more than the sample's products.
+func getCardsAmount(request dto.ProductRequest, sampleSize int) int {
+	if request.Amount > 0 {
+		return min(request.Amount, sampleSize)
 	}
+	return sampleSize
 }
 
 // getHtmlPage gets the raw html (through the open API path) using the filters and the main url's template.
-func (w WildberriesAPI) getHtmlPage(url string, request dto.ProductRequest) (string, error) {
+// The page is scrolled while the amount of the products' cards keeps growing until the amount of the cards
+// is loaded or the loading's deadline is reached.
+func (w WildberriesAPI) getHtmlPage(ctx context.Context, url string, amount int) (string, entities.PageLoading, error) {
 	const serviceType = "wildberries.service.html-page-getter"
 
-	var err error
 	var html string
+	var cards int
+	var reason entities.LoadingStopReason
 
-	if request.Amount == "min" {
-		_, err = chromedp.RunResponse(w.ctx,
-			chromedp.Navigate(url),
-			chromedp.InnerHTML(fmt.Sprintf("[class='%s']", productContainerClassName), &html),
-		)
-	} else if request.Amount == "max" {
-		_, err = chromedp.RunResponse(w.ctx,
-			chromedp.Navigate(url),
-			chromedp.Sleep(3000*time.Millisecond+w.loadCoeff),
-			chromedp.KeyEvent(kb.End),
-			chromedp.Sleep(1000*time.Millisecond+w.loadCoeff),
-			chromedp.KeyEvent(kb.End),
-			chromedp.Sleep(1000*time.Millisecond+w.loadCoeff),
-			chromedp.KeyEvent(kb.End),
-			chromedp.Sleep(1000*time.Millisecond+w.loadCoeff),
-			chromedp.KeyEvent(kb.End),
-			chromedp.Sleep(4000*time.Millisecond+w.loadCoeff),
+	driverCtx, release, err := w.tabs.Acquire(ctx)
+
+	if err != nil {
+		w.logger.Warn(fmt.Sprintf("error of the %s: %v", serviceType, err))
+		return "", entities.PageLoading{}, err
+	}
+	defer release()
+
+	loader := newPageLoader(amount, time.Now().Add(loadingDeadline+w.loadCoeff))
+
+	_, err = chromedp.RunResponse(driverCtx,
+		chromedp.Navigate(url),
+		chromedp.WaitReady(fmt.Sprintf("[class='%s']", productContainerClassName)),
+	)
+
+	for err == nil && len(reason) == 0 {
+		if err = chromedp.Run(driverCtx, chromedp.Evaluate(cardsCountScript, &cards)); err != nil {
+			break
+		}
+
+		if reason = loader.check(cards, time.Now()); len(reason) == 0 {
+			err = chromedp.Run(driverCtx,
+				chromedp.KeyEvent(kb.End),
+				chromedp.Sleep(scrollDelay+w.loadCoeff),
+			)
+		}
+	}
+
+	if err == nil {
+		err = chromedp.Run(driverCtx,
 			chromedp.InnerHTML(fmt.Sprintf("[class='%s']", productContainerClassName), &html),
 		)
 	}
 
 	if err != nil {
 		w.logger.Error(fmt.Sprintf("error of the %s: %v: %v", serviceType, api.ErrChromeDriver, err))
-		return "", fmt.Errorf("%w: %v", api.ErrChromeDriver, err)
+		return "", entities.PageLoading{}, fmt.Errorf("%w: %v", api.ErrChromeDriver, err)
 	}
 
-	return html, nil
+	loading := loader.getLoading(reason)
+	w.logger.Info(fmt.Sprintf("%s: %d cards were loaded: %s", serviceType, loading.Cards, loading.StopReason))
+
+	return html, loading, nil
 }
 
-// getProductsSample gets the json-view structs of the products connected with the current "sample".
-func (w WildberriesAPI) getProductSample(url string) ([]wildberriesProduct, error) {
+// errServerFailure marks the market's 5xx responses: unlike the rest failed responses they're often transient.
+var errServerFailure = errors.New("the market's service has failed")
+
+// requestProductSample makes the only attempt of getting the json-view structs of the products.
+func (w WildberriesAPI) requestProductSample(ctx context.Context, url string) ([]wildberriesProduct, error) {
 	const serviceType = "wildberries.service.search.wb.ru-products-getter"
 
 	sample := struct {
 		Data struct {
 			Products []wildberriesProduct `json:"products"`
 		} `json:"data"`
-	}{
-		Data: struct {
-			Products []wildberriesProduct `json:"products"`
-		}{
-			make([]wildberriesProduct, 0, 750),
-		},
+	}{}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", api.ErrServiceResponse, err)
 	}
 
-	for respBody := []byte{}; len(sample.Data.Products) < 10; {
-		respBody = respBody[:0]
-		sample.Data.Products = sample.Data.Products[:0]
+	resp, err := http.DefaultClient.Do(req)
 
-		resp, err := http.Get(url)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", api.ErrServiceResponse, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return nil, fmt.Errorf("%w: %w: %w: status %d", api.ErrServiceResponse, api.ErrServiceBlocked,
+			api.ErrServiceThrottling, resp.StatusCode)
+	} else if resp.StatusCode == http.StatusForbidden {
+		return nil, fmt.Errorf("%w: %w: status %d", api.ErrServiceResponse, api.ErrServiceBlocked, resp.StatusCode)
+	} else if resp.StatusCode >= http.StatusInternalServerError {
+		return nil, fmt.Errorf("%w: %w: status %d", api.ErrServiceResponse, errServerFailure, resp.StatusCode)
+	} else if resp.StatusCode > 299 {
+		return nil, fmt.Errorf("%w: status %d", api.ErrServiceResponse, resp.StatusCode)
+	}
 
-		if err != nil || resp.StatusCode > 299 {
-			w.logger.Warn(fmt.Sprintf("error of the %v: %v: %v", serviceType, api.ErrServiceResponse, err))
-			return nil, fmt.Errorf("%w: %v", api.ErrServiceResponse, err)
-		}
-		defer resp.Body.Close()
+	respBody, err := api.ReadResponseBody(resp.Body, w.logger, serviceType)
 
-		respBody, err := api.ReadResponseBody(resp.Body, w.logger, serviceType)
+	if err != nil {
+		return nil, err
+	}
 
-		if err != nil {
-			return nil, fmt.Errorf("error of the %v: error of the reading response body: %v",
-				serviceType, err)
+	if err := json.Unmarshal(respBody, &sample); err != nil {
+		return nil, fmt.Errorf("%w: %v", api.ErrJSONResponseParsing, err)
+	}
+
+	if len(sample.Data.Products) == 0 {
+		return nil, api.ErrEmptySample
+	}
+
+	return sample.Data.Products, nil
+}
+
+// isRetryable checks whether the failed attempt of getting the sample can be repeated:
+// only the throttling and the market's 5xx responses are repeated. The empty sample of the valid
+// response is accepted at once because the query can really have got no products.
+func isRetryable(err error) bool {
+	return errors.Is(err, api.ErrServiceThrottling) || errors.Is(err, errServerFailure)
+}
+
+// getProductsSample gets the json-view structs of the products connected with the current "sample".
+// The throttled and the 5xx attempts are repeated with the growing backoff up to the max amount of the attempts:
+// the samples with any amount of the products are accepted.
+func (w WildberriesAPI) getProductSample(ctx context.Context, url string) ([]wildberriesProduct, error) {
+	const serviceType = "wildberries.service.search.wb.ru-products-getter"
+
+	var backoff = w.backoff
+
+	for attempt := 1; ; attempt++ {
+		products, err := w.requestProductSample(ctx, url)
+
+		if err == nil {
+			return products, nil
 		}
 
-		err = json.Unmarshal(respBody, &sample)
+		if attempt == maxAttempts || !isRetryable(err) {
+			w.logger.Warn(fmt.Sprintf("error of the %v: attempt %d: %v", serviceType, attempt, err))
+			return nil, err
+		}
 
-		if err != nil {
-			w.logger.Error(fmt.Sprintf("error of parsing the json: %v", err))
-			return nil, nil
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("%w: %v", api.ErrConnectionClosed, ctx.Err())
+		case <-time.After(backoff):
+			backoff *= 2
 		}
 	}
-
-	return sample.Data.Products, nil
 }
 
 // getProducts is the main function of getting the products with set filters.
 // The current geo-string defines the Moscow info.
-func (w WildberriesAPI) getProducts(ctx echo.Context, request dto.ProductRequest, filters ...string) (entities.ProductSample, error) {
+func (w WildberriesAPI) getProducts(ctx context.Context, request dto.ProductRequest, filters ...string) (entities.ProductSample, error) {
 	const serviceType = "wildberries.service.main-products-getter"
 	var products = make([]entities.Product, 0, 100)
 
-	if !request.Async && api.IsConnectionClosed(ctx) {
+	if api.IsConnectionClosed(ctx) {
 		w.logger.Warn(fmt.Sprintf("error of processing the %v: %v", serviceType, api.ErrConnectionClosed))
 		return entities.ProductSample{}, fmt.Errorf("error of processing the %v: %w", serviceType, api.ErrConnectionClosed)
 	}
 
-	sample, err := w.getProductSample(w.view.getHiddenApiURL(request, filters))
+	// the market doesn't mark the preordered products so their sample can't be got.
+	if request.Availability == dto.PreorderAvailability {
+		return entities.ProductSample{}, fmt.Errorf("error of processing the %v: %w: the %v availability",
+			serviceType, api.ErrUnsupportedRequest, request.Availability)
+	}
 
-	if err != nil {
+	rawSample, err := w.getProductSample(ctx, w.view.getHiddenApiURL(request, filters))
+
+	if errors.Is(err, api.ErrEmptySample) {
+		return entities.NewProductSample(products, w.view.getOpenApiURL(request, filters), entities.Wildberries), nil
+	} else if err != nil {
+		return entities.ProductSample{}, err
+	}
+
+	if err := w.drift.Check(w.parser.getDriftReport(rawSample)); err != nil {
 		return entities.ProductSample{}, err
 	}
 
-	if !request.Async && api.IsConnectionClosed(ctx) {
+	sample, rejected := w.parser.validateProducts(rawSample)
+	sample = w.parser.filterAvailable(sample, request.Availability)
+
+	if api.IsConnectionClosed(ctx) {
 		w.logger.Warn(fmt.Sprintf("error of processing the %v: %v", serviceType, api.ErrConnectionClosed))
 		return entities.ProductSample{}, fmt.Errorf("error of processing the %v: %w", serviceType, api.ErrConnectionClosed)
 	}
 
 	htmlSourceLink := w.view.getOpenApiURL(request, filters)
 
-	imageLinks := make([]string, 0, 100)
+	imageLinks := make(map[int]string)
+	var loading *entities.PageLoading
 
-	if !request.FlagNoImage {
-		html, err := w.getHtmlPage(htmlSourceLink, request)
+	if !request.FlagNoImage && w.images == BrowserImages {
+		html, pageLoading, err := w.getHtmlPage(ctx, htmlSourceLink, getCardsAmount(request, len(sample)))
 
 		if err != nil {
 			return entities.ProductSample{}, err
 		}
 
 		imageLinks = w.parser.parseImageLinks(html)
+		loading = &pageLoading
 	}
 
-	for i, j := 0, 0; i != len(sample); i++ {
+	for i := 0; i != len(sample); i++ {
 		var image string
 
-		if j < len(imageLinks) && !request.FlagNoImage {
-			image = imageLinks[j]
-			j++
+		if !request.FlagNoImage && w.images == BrowserImages {
+			image = entities.NoImageLink
+
+			if link, flagExist := imageLinks[sample[i].ID]; flagExist {
+				image = link
+			}
+		} else if !request.FlagNoImage {
+			image = w.view.getImageLink(sample[i].ID)
 		}
 
+		availability, quantity, delivery := w.parser.getStock(sample[i].wildberriesProduct)
+
 		products = append(products, entities.Product{
 			Name:     sample[i].Name,
 			Brand:    sample[i].Brand,
-			Price:    entities.NewPrice(sample[i].Sizes[0].Price.Basic/100, sample[i].Sizes[0].Price.Total/100),
+			Price:    sample[i].price,
 			Supplier: sample[i].Supplier,
 			Links: entities.ProductLink{
 				URL:       w.view.getProductCatalogLink(sample[i].ID),
 				ImageLink: image,
 			},
+			Availability: availability,
+			Quantity:     quantity,
+			Delivery:     delivery,
 		})
 	}
 
-	return entities.NewProductSample(products, htmlSourceLink, entities.Wildberries), nil
+	productSample := entities.NewProductSample(products, htmlSourceLink, entities.Wildberries)
+	productSample.Loading = loading
+	productSample.Rejected = rejected
+
+	return productSample, nil
 }
 
 // GetProducts gets the products without any filters.
-func (w WildberriesAPI) GetProducts(ctx echo.Context, request dto.ProductRequest) (entities.ProductSample, error) {
+func (w WildberriesAPI) GetProducts(ctx context.Context, request dto.ProductRequest) (entities.ProductSample, error) {
 	return w.getProducts(ctx, request, sortID, string(request.Sort))
 }
 
 // GetProductsByPriceRange gets the products with filter by price range.
-func (w WildberriesAPI) GetProductsWithPriceRange(ctx echo.Context, request dto.ProductRequest) (entities.ProductSample, error) {
+func (w WildberriesAPI) GetProductsWithPriceRange(ctx context.Context, request dto.ProductRequest) (entities.ProductSample, error) {
 	return w.getProducts(ctx, request, sortID, string(request.Sort),
 		priceRangeID, w.view.getPriceRangeView(request.PriceRange.PriceDown, request.PriceRange.PriceUp))
 }
 
 // GetProductsByExactPrice gets the products with filter by price
-// in range [exactPrice, exactPrice + 10% off exactPrice].
-func (w WildberriesAPI) GetProductsWithExactPrice(ctx echo.Context, request dto.ProductRequest) (entities.ProductSample, error) {
+// in the tolerance window around the exact price.
+func (w WildberriesAPI) GetProductsWithExactPrice(ctx context.Context, request dto.ProductRequest) (entities.ProductSample, error) {
+	priceRange := request.GetExactPriceRange()
+
 	return w.getProducts(ctx, request, sortID, string(request.Sort),
-		priceRangeID, w.view.getPriceRangeView(request.ExactPrice, int(float32(request.ExactPrice)*1.1)))
+		priceRangeID, w.view.getPriceRangeView(priceRange.PriceDown, priceRange.PriceUp))
 }
 
 // GetProductsByBestPrice gets the products with filter by min price.
-func (w WildberriesAPI) GetProductsWithBestPrice(ctx echo.Context, request dto.ProductRequest) (entities.ProductSample, error) {
+func (w WildberriesAPI) GetProductsWithBestPrice(ctx context.Context, request dto.ProductRequest) (entities.ProductSample, error) {
 	return w.getProducts(ctx, request, sortID, string(dto.PriceUpSort))
 }
+
+// GetPagesConcurrency returns the amount of the pages that can be got simultaneously:
+// the pages with the image links parsed by the browser are got through the only browser's context.
+func (w WildberriesAPI) GetPagesConcurrency(request dto.ProductRequest) int {
+	if request.FlagNoImage || w.images == IDImages {
+		return pagesConcurrency
+	}
+	return 1
+}
@@ -4,12 +4,17 @@ import (
 	"fmt"
 	"log/slog"
 	"net/url"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/anaskhan96/soup"
 
 	"github.com/MaKcm14/price-service/internal/entities/dto"
 	"github.com/MaKcm14/price-service/internal/repository/api"
+	"github.com/MaKcm14/price-service/pkg/entities"
 )
 
 // URL paths' consts.
@@ -19,6 +24,25 @@ const (
 	wildberriesOpenAPIPath = "https://www.wildberries.ru/catalog/0/search.aspx"
 )
 
+// images' consts.
+const (
+	imageLinkTemplate = "https://basket-%02d.wbbasket.ru/vol%d/part%d/%d/images/c516x688/1.webp"
+
+	IDImages      ImagesSource = "id"
+	BrowserImages ImagesSource = "browser"
+)
+
+// basketsBounds defines the upper bounds of the products' volumes stored in every basket:
+// the basket's number is the bound's index plus 1. The volumes over the last bound are stored
+// in the next basket.
+var basketsBounds = []int{
+	143, 287, 431, 719, 1007, 1061, 1115, 1169, 1313, 1601, 1655, 1919, 2045,
+	2189, 2405, 2621, 2837, 3053, 3269, 3485, 3701, 3917, 4133, 4349, 4565,
+}
+
+// ImagesSource defines the source of the products' image links.
+type ImagesSource string
+
 // URL query params' consts.
 const (
 	priceRangeID = "priceU"
@@ -28,25 +52,84 @@ const (
 	pageID       = "page"
 )
 
+const (
+	// pagesConcurrency is the max amount of the pages that can be got simultaneously without the images.
+	pagesConcurrency = 3
+)
+
 // parsing's consts.
 const (
 	mainProductsTagName       = "article"
+	articleIDAttrName         = "data-nm-id"
 	imageClassName            = "j-thumbnail"
 	productContainerClassName = "product-card-list"
 )
 
+// validation's rejection reasons.
+const (
+	rejectNoName       = "no_name"
+	rejectNoSizes      = "no_sizes"
+	rejectZeroPrice    = "zero_price"
+	rejectInvalidPrice = "invalid_price"
+)
+
+// retrying's consts.
+const (
+	// maxAttempts is the max amount of the attempts of getting the products' sample.
+	maxAttempts = 3
+
+	// retryBackoff is the time of waiting before the second attempt: it's doubled for every next attempt.
+	retryBackoff = 300 * time.Millisecond
+)
+
+// scrolling's consts.
+const (
+	// loadingDeadline is the max time of the page's loading.
+	loadingDeadline = 15 * time.Second
+
+	// scrollDelay is the time that is given to the page to load the next products' cards.
+	scrollDelay = 1000 * time.Millisecond
+
+	// maxStableScrolls is the amount of the scrolls in a row that didn't load any new cards
+	// after which the page is considered loaded.
+	maxStableScrolls = 2
+)
+
+// cardsCountScript returns the amount of the products' cards that are loaded on the page.
+var cardsCountScript = fmt.Sprintf("document.querySelectorAll(\"[class='%s'] %s\").length",
+	productContainerClassName, mainProductsTagName)
+
 type (
 	wildberriesProduct struct {
-		ID       int    `json:"id"`
-		Brand    string `json:"brand"`
-		Name     string `json:"name"`
-		Supplier string `json:"supplier"`
-		Sizes    []struct {
-			Price struct {
-				Basic int
-				Total int
-			} `json:"price"`
-		} `json:"sizes"`
+		ID            int               `json:"id"`
+		Brand         string            `json:"brand"`
+		Name          string            `json:"name"`
+		Supplier      string            `json:"supplier"`
+		Sizes         []wildberriesSize `json:"sizes"`
+		TotalQuantity *int              `json:"totalQuantity"`
+
+		// DeliveryTime and DeliveryExtraTime are the hours of the delivery to the warehouse
+		// and from it to the pickup point.
+		DeliveryTime      int `json:"time1"`
+		DeliveryExtraTime int `json:"time2"`
+	}
+
+	wildberriesSize struct {
+		Price struct {
+			Basic int
+			Total int
+		} `json:"price"`
+		Stocks []wildberriesStock `json:"stocks"`
+	}
+
+	wildberriesStock struct {
+		Quantity int `json:"qty"`
+	}
+
+	// validProduct defines the product that has passed the validation with its price.
+	validProduct struct {
+		wildberriesProduct
+		price entities.Price
 	}
 
 	// wildberriesViewer defines the logic of the queries' parameters format.
@@ -58,8 +141,53 @@ type (
 	wildberriesParser struct {
 		logger *slog.Logger
 	}
+
+	// pageLoader defines the logic of the page's scrolling: the page is scrolled
+	// while the amount of the loaded cards keeps growing.
+	pageLoader struct {
+		amount   int
+		deadline time.Time
+		cards    int
+		stable   int
+	}
 )
 
+func newPageLoader(amount int, deadline time.Time) pageLoader {
+	return pageLoader{
+		amount:   amount,
+		deadline: deadline,
+		cards:    -1,
+	}
+}
+
+// check returns the reason of the loading's stop according to the current amount of the loaded cards.
+// It returns the empty reason if the page must be scrolled again.
+func (l *pageLoader) check(cards int, now time.Time) entities.LoadingStopReason {
+	if cards > l.cards {
+		l.stable = 0
+	} else {
+		l.stable++
+	}
+	l.cards = cards
+
+	if cards >= l.amount {
+		return entities.LoadingStopEnough
+	} else if l.stable >= maxStableScrolls {
+		return entities.LoadingStopStable
+	} else if !now.Before(l.deadline) {
+		return entities.LoadingStopDeadline
+	}
+	return ""
+}
+
+// getLoading returns the result of the page's loading.
+func (l *pageLoader) getLoading(reason entities.LoadingStopReason) entities.PageLoading {
+	return entities.PageLoading{
+		Cards:      max(l.cards, 0),
+		StopReason: reason,
+	}
+}
+
 // getOpenApiPath returns the correct URL's path for wildberries open API.
 // It uses with the origin "https://www.wildberries.ru".
 func (v wildberriesViewer) getOpenApiPath(request dto.ProductRequest, filters []string) string {
@@ -110,6 +238,14 @@ func (v wildberriesViewer) getPriceRangeView(priceDown int, priceUp int) string
 	return fmt.Sprintf("%v00;%v00", priceDown, priceUp)
 }
 
+// getImageLink returns the link of the product's main image derived from the product's ID.
+func (v wildberriesViewer) getImageLink(productID int) string {
+	vol := productID / 100000
+	basket := sort.SearchInts(basketsBounds, vol) + 1
+
+	return fmt.Sprintf(imageLinkTemplate, basket, vol, productID/1000, productID)
+}
+
 func (v wildberriesViewer) getProductCatalogLink(productID int) string {
 	return fmt.Sprintf("https://www.wildberries.ru/catalog/%d/detail.aspx", productID)
 }
@@ -128,7 +264,8 @@ func (p wildberriesViewer) getOpenApiURL(request dto.ProductRequest, filters []s
 }
 
 // parseImageLinks parses the image links for the products from the current html-page.
-func (p wildberriesParser) parseImageLinks(html string) []string {
+// The links are mapped by the products' article IDs set in the cards.
+func (p wildberriesParser) parseImageLinks(html string) map[int]string {
 	const serviceType = "wildberries.service.image-links-getter"
 
 	if !strings.Contains(html, mainProductsTagName) {
@@ -136,15 +273,171 @@ func (p wildberriesParser) parseImageLinks(html string) []string {
 		return nil
 	}
 
-	var imageLinks = make([]string, 0, 100)
+	var imageLinks = make(map[int]string, 100)
 
 	for _, tag := range soup.HTMLParse(html).FindAll(mainProductsTagName) {
+		id, err := strconv.Atoi(tag.Attrs()[articleIDAttrName])
+
+		if err != nil {
+			continue
+		}
+
 		link := tag.Find("img", "class", imageClassName)
 
 		if link.Pointer != nil {
-			imageLinks = append(imageLinks, link.Attrs()["src"])
+			imageLinks[id] = link.Attrs()["src"]
 		}
 	}
 
 	return imageLinks
 }
+
+// validateProduct returns the product's price got from its sizes or the reason of the product's rejection:
+// the price of the cheapest size is taken and the range of the sizes' prices is set if they're different.
+func (p wildberriesParser) validateProduct(product wildberriesProduct) (entities.Price, string) {
+	if len(strings.TrimSpace(product.Name)) == 0 {
+		return entities.Price{}, rejectNoName
+	} else if len(product.Sizes) == 0 {
+		return entities.Price{}, rejectNoSizes
+	}
+
+	var cheapest, maxTotal = -1, 0
+
+	for i, size := range product.Sizes {
+		if size.Price.Total <= 0 {
+			continue
+		}
+
+		if cheapest == -1 || size.Price.Total < product.Sizes[cheapest].Price.Total {
+			cheapest = i
+		}
+		maxTotal = max(maxTotal, size.Price.Total)
+	}
+
+	if cheapest == -1 {
+		return entities.Price{}, rejectZeroPrice
+	}
+
+	minPrice := product.Sizes[cheapest].Price
+	price := entities.NewPrice(minPrice.Basic/100, minPrice.Total/100)
+
+	if price.DiscountPrice == 0 {
+		return entities.Price{}, rejectInvalidPrice
+	}
+
+	if maxTotal != minPrice.Total {
+		price.Range = &entities.PriceRange{
+			Min: minPrice.Total / 100,
+			Max: maxTotal / 100,
+		}
+	}
+
+	return price, ""
+}
+
+// validateProducts returns the products that have passed the validation and the amount
+// of the rejected products by the reasons: every rejection is logged.
+func (p wildberriesParser) validateProducts(sample []wildberriesProduct) ([]validProduct, map[string]int) {
+	const serviceType = "wildberries.service.products-validator"
+
+	var products = make([]validProduct, 0, len(sample))
+	var rejected map[string]int
+
+	for _, product := range sample {
+		price, reason := p.validateProduct(product)
+
+		if len(reason) == 0 {
+			products = append(products, validProduct{wildberriesProduct: product, price: price})
+			continue
+		}
+
+		if rejected == nil {
+			rejected = make(map[string]int)
+		}
+		rejected[reason]++
+
+		p.logger.Debug(fmt.Sprintf("%s: the product %d was rejected: %s", serviceType, product.ID, reason))
+	}
+
+	if len(rejected) != 0 {
+		p.logger.Warn(fmt.Sprintf("%s: %d of %d products were rejected: %v", serviceType,
+			len(sample)-len(products), len(sample), rejected))
+	}
+
+	return products, rejected
+}
+
+// getStock returns the product's availability, its quantity and the estimate of its delivery.
+// The availability is unknown if the product hasn't got any stock fields.
+func (p wildberriesParser) getStock(product wildberriesProduct) (entities.Availability, *int, *entities.ProductDelivery) {
+	var delivery *entities.ProductDelivery
+
+	if hours := product.DeliveryTime + product.DeliveryExtraTime; hours > 0 {
+		delivery = &entities.ProductDelivery{Hours: hours}
+	}
+
+	quantity := product.TotalQuantity
+
+	if quantity == nil {
+		for _, size := range product.Sizes {
+			if size.Stocks == nil {
+				continue
+			} else if quantity == nil {
+				quantity = new(int)
+			}
+
+			for _, stock := range size.Stocks {
+				*quantity += stock.Quantity
+			}
+		}
+	}
+
+	if quantity == nil {
+		return entities.UnknownAvailability, nil, delivery
+	} else if *quantity <= 0 {
+		return entities.OutOfStock, quantity, delivery
+	}
+	return entities.InStock, quantity, delivery
+}
+
+// filterAvailable returns the products that fit the requested availability.
+func (p wildberriesParser) filterAvailable(sample []validProduct, availability dto.AvailabilityType) []validProduct {
+	products := make([]validProduct, 0, len(sample))
+
+	for _, product := range sample {
+		if stock, _, _ := p.getStock(product.wildberriesProduct); availability.Allows(stock) {
+			products = append(products, product)
+		}
+	}
+
+	return products
+}
+
+// getDriftReport returns the report of the sample's products with the missing critical fields.
+func (p wildberriesParser) getDriftReport(sample []wildberriesProduct) api.DriftReport {
+	report := api.NewDriftReport()
+
+	for _, product := range sample {
+		missing := make([]string, 0, 4)
+
+		if product.ID == 0 {
+			missing = append(missing, "id")
+		}
+
+		if len(product.Name) == 0 {
+			missing = append(missing, "name")
+		}
+
+		if len(product.Sizes) == 0 {
+			missing = append(missing, "sizes")
+		} else if slices.IndexFunc(product.Sizes, func(size wildberriesSize) bool {
+			return size.Price.Total != 0
+		}) == -1 {
+			missing = append(missing, "price")
+		}
+
+		report.Add(missing...)
+	}
+
+	return report
+}
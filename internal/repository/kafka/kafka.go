@@ -9,7 +9,6 @@ import (
 	"github.com/IBM/sarama"
 	"github.com/MaKcm14/price-service/internal/controller/chttp"
 	"github.com/MaKcm14/price-service/internal/entities/dto"
-	"github.com/MaKcm14/price-service/pkg/entities"
 )
 
 // Producer defines the logic of kafka's producing.
@@ -40,24 +39,59 @@ func NewProducer(log *slog.Logger, brokers []string) (Producer, error) {
 }
 
 // SendProductsMessage sends the products response to the client.
-func (p Producer) SendProductsMessage(products []entities.ProductSample, request dto.ProductRequest) {
+func (p Producer) SendProductsMessage(response dto.FilterResponse, request dto.ProductRequest) {
 	const op = "kafka.send-products-message"
 
-	response := chttp.NewProductResponse(products)
-	buf, _ := json.Marshal(response)
+	buf, _ := json.Marshal(chttp.NewProductResponse(response))
 
-	recordHeaders := make([]sarama.RecordHeader, 0, len(request.Headers))
+	p.sendMessage(op, buf, p.getRecordHeaders(request))
+}
+
+// SendCancelledMessage sends the terminal message of the cancelled async job to the client:
+// it contains the job's view and the original extra headers of the request.
+func (p Producer) SendCancelledMessage(job dto.Job, request dto.ProductRequest) {
+	const op = "kafka.send-cancelled-message"
+
+	buf, _ := json.Marshal(chttp.NewJobResponse(job))
+
+	recordHeaders := append(p.getRecordHeaders(request), sarama.RecordHeader{
+		Key:   []byte(dto.JobStateHeader),
+		Value: []byte(job.State),
+	})
+
+	p.sendMessage(op, buf, recordHeaders)
+}
+
+// getRecordHeaders returns the message's headers: the extra headers of the request and the job's ID.
+func (p Producer) getRecordHeaders(request dto.ProductRequest) []sarama.RecordHeader {
+	recordHeaders := make([]sarama.RecordHeader, 0, len(request.Headers)+2)
 
 	for key, val := range request.Headers {
+		if key == dto.JobIDHeader || key == dto.JobStateHeader {
+			continue
+		}
+
 		recordHeaders = append(recordHeaders, sarama.RecordHeader{
 			Key:   []byte(key),
 			Value: []byte(val),
 		})
 	}
 
+	if len(request.JobID) != 0 {
+		recordHeaders = append(recordHeaders, sarama.RecordHeader{
+			Key:   []byte(dto.JobIDHeader),
+			Value: []byte(request.JobID),
+		})
+	}
+
+	return recordHeaders
+}
+
+// sendMessage sends the message to the products' topic: the sending is repeated a few times if it fails.
+func (p Producer) sendMessage(op string, value []byte, recordHeaders []sarama.RecordHeader) {
 	msg := &sarama.ProducerMessage{
 		Topic:   productsTopicName,
-		Value:   sarama.ByteEncoder(buf),
+		Value:   sarama.ByteEncoder(value),
 		Headers: recordHeaders,
 	}
 
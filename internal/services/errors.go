@@ -5,4 +5,92 @@ import "errors"
 var (
 	ErrGettingProducts = errors.New("error of getting the products from all the market/markets")
 	ErrMarketApi       = errors.New("error of getting the market api")
+	ErrMarketTimeout   = errors.New("the market api hasn't responded in the set time")
+	ErrJobNotFound     = errors.New("the async job wasn't found")
+	ErrJobCreation     = errors.New("error of creating the async job")
+	ErrJobDeadline     = errors.New("the async job hasn't finished in the set time")
+	ErrJobCancelled    = errors.New("the async job was cancelled")
+	ErrJobFinished     = errors.New("the async job is already finished")
+	ErrJobPanic        = errors.New("the async job has panicked")
+	ErrJobsStopped     = errors.New("the async jobs aren't accepted anymore")
+	ErrJobsQueueFull   = errors.New("the queue of the async jobs is full")
+	ErrMarketPanic     = errors.New("the market api has panicked")
 )
+
+// The errors of the markets' apis: the apis wrap them so the market's status and its error's code
+// can be defined without the knowledge of the concrete api.
+var (
+	ErrByPassServiceResponse = errors.New("error of getting the response from by-pass-service")
+	ErrByPassBadRequest      = errors.New("the by-pass-service has rejected the request")
+	ErrByPassVersion         = errors.New("the by-pass-service's protocol version isn't supported")
+	ErrByPassUpstream        = errors.New("the market has limited the by-pass-service's requests")
+	ErrByPassInternal        = errors.New("the by-pass-service has failed")
+	ErrServiceResponse       = errors.New("error of getting the response")
+	ErrServiceBlocked        = errors.New("the market's service has limited the requests")
+	ErrServiceThrottling     = errors.New("the market's service has throttled the requests")
+	ErrChromeDriver          = errors.New("error of the chrome driver's interaction")
+	ErrChromePullExhausted   = errors.New("there aren't any free tabs of the browser")
+	ErrChromePullClosed      = errors.New("the pull of the browser's tabs is closed")
+	ErrBufferReading         = errors.New("error of reading the data")
+	ErrConnectionClosed      = errors.New("the client has closed the connection")
+	ErrJSONResponseParsing   = errors.New("error of parsing the json-data")
+	ErrEmptySample           = errors.New("the market's sample is empty")
+	ErrSchemaDrift           = errors.New("the market's response schema has drifted")
+	ErrUnsupportedRequest    = errors.New("the market doesn't support the request's params")
+)
+
+// errorsCodes defines the machine-readable codes of the markets' apis' errors.
+// The order matters: the first matched error defines the code.
+var errorsCodes = []struct {
+	err  error
+	code string
+}{
+	{ErrByPassUpstream, "by_pass_upstream_overflow"},
+	{ErrServiceThrottling, "service_throttling"},
+	{ErrServiceBlocked, "service_blocked"},
+	{ErrConnectionClosed, "connection_closed"},
+	{ErrUnsupportedRequest, "unsupported_request"},
+	{ErrSchemaDrift, "schema_drift"},
+	{ErrJSONResponseParsing, "json_response_parsing"},
+	{ErrEmptySample, "empty_sample"},
+	{ErrBufferReading, "buffer_reading"},
+	{ErrChromePullExhausted, "chrome_pull_exhausted"},
+	{ErrChromePullClosed, "chrome_pull_closed"},
+	{ErrChromeDriver, "chrome_driver"},
+	{ErrByPassVersion, "by_pass_version"},
+	{ErrByPassBadRequest, "by_pass_bad_request"},
+	{ErrByPassInternal, "by_pass_internal"},
+	{ErrByPassServiceResponse, "by_pass_service_response"},
+	{ErrServiceResponse, "service_response"},
+}
+
+// ErrorCode returns the machine-readable code of the market api's error.
+// It returns "unknown" if the error isn't the market api's error.
+func ErrorCode(err error) string {
+	for _, errCode := range errorsCodes {
+		if errors.Is(err, errCode.err) {
+			return errCode.code
+		}
+	}
+	return "unknown"
+}
+
+// terminalErrors defines the markets' apis' errors that are repeated on every request with the same params.
+var terminalErrors = []error{
+	ErrMarketApi,
+	ErrUnsupportedRequest,
+	ErrByPassBadRequest,
+	ErrByPassVersion,
+	ErrSchemaDrift,
+}
+
+// IsTerminal checks whether the market api's error is repeated on every request with the same params
+// so the market's request isn't worth repeating.
+func IsTerminal(err error) bool {
+	for _, terminalErr := range terminalErrors {
+		if errors.Is(err, terminalErr) {
+			return true
+		}
+	}
+	return false
+}
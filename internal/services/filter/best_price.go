@@ -0,0 +1,68 @@
+package filter
+
+import (
+	"math"
+	"strings"
+
+	"github.com/MaKcm14/price-service/internal/entities/dto"
+	"github.com/MaKcm14/price-service/pkg/entities"
+)
+
+// getBestPriceProducts returns the top cheapest products across all the markets' samples.
+// The products without the price are ignored.
+func getBestPriceProducts(samples []entities.ProductSample, top int) []entities.Product {
+	products := make([]entities.Product, 0, top)
+
+	for _, product := range mergeSamples(samples, dto.PriceUpSort) {
+		if len(products) == top {
+			break
+		} else if product.Price.DiscountPrice == 0 {
+			continue
+		}
+		products = append(products, product)
+	}
+
+	return products
+}
+
+// newBestPriceSummary returns the comparison of the markets' minimal prices.
+// It returns nil if there isn't any product with the price in the samples.
+func newBestPriceSummary(samples []entities.ProductSample) *entities.BestPriceSummary {
+	var summary = entities.BestPriceSummary{
+		MarketsMinPrices: make(map[string]int),
+	}
+	var maxMinPrice int
+
+	for _, sample := range samples {
+		minPrice := 0
+
+		for _, product := range sample.Products {
+			if price := product.Price.DiscountPrice; price != 0 && (minPrice == 0 || price < minPrice) {
+				minPrice = price
+			}
+		}
+
+		if minPrice == 0 {
+			continue
+		}
+		summary.MarketsMinPrices[strings.ToLower(sample.Market)] = minPrice
+
+		if summary.MinPrice == 0 || minPrice < summary.MinPrice {
+			summary.MinPrice = minPrice
+			summary.Market = strings.ToLower(sample.Market)
+		}
+
+		if minPrice > maxMinPrice {
+			maxMinPrice = minPrice
+		}
+	}
+
+	if summary.MinPrice == 0 {
+		return nil
+	}
+
+	summary.Spread = maxMinPrice - summary.MinPrice
+	summary.SpreadPercent = math.Round(float64(summary.Spread)*10000/float64(summary.MinPrice)) / 100
+
+	return &summary
+}
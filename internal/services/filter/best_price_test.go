@@ -0,0 +1,89 @@
+package filter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/MaKcm14/price-service/pkg/entities"
+)
+
+func TestGetBestPriceProductsPositiveCases(t *testing.T) {
+	t.Run("Positive Case: the top is less than the amount of the products", func(t *testing.T) {
+		products := getBestPriceProducts(getTestMergeSamples(), 2)
+
+		assert.Equal(t, []string{"2-a", "1-b"}, getTestProductsNames(products))
+	})
+
+	t.Run("Positive Case: the products without the price are ignored", func(t *testing.T) {
+		products := getBestPriceProducts(getTestMergeSamples(), 10)
+
+		assert.Equal(t, []string{"2-a", "1-b", "2-b", "1-a"}, getTestProductsNames(products))
+	})
+}
+
+func TestNewBestPriceSummaryPositiveCases(t *testing.T) {
+	samples := []entities.ProductSample{
+		{
+			Market: nameTestMarket1,
+			Products: []entities.Product{
+				{Price: entities.Price{DiscountPrice: 150}},
+				{Price: entities.Price{DiscountPrice: 120}},
+			},
+		},
+		{
+			Market: nameTestMarket2,
+			Products: []entities.Product{
+				{Price: entities.Price{}},
+				{Price: entities.Price{DiscountPrice: 100}},
+			},
+		},
+	}
+
+	summary := newBestPriceSummary(samples)
+
+	if assert.NotNil(t, summary) {
+		assert.Equal(t, entities.BestPriceSummary{
+			Market:   "testmarket2",
+			MinPrice: 100,
+			MarketsMinPrices: map[string]int{
+				"testmarket1": 120,
+				"testmarket2": 100,
+			},
+			Spread:        20,
+			SpreadPercent: 20,
+		}, *summary)
+		assert.Contains(t, summary.MarketsMinPrices, summary.Market)
+	}
+}
+
+func TestNewBestPriceSummaryExtremeCases(t *testing.T) {
+	t.Run("Extreme Case: there isn't any product with the price", func(t *testing.T) {
+		summary := newBestPriceSummary([]entities.ProductSample{
+			{
+				Market:   nameTestMarket1,
+				Products: []entities.Product{{}},
+			},
+		})
+
+		assert.Nil(t, summary)
+	})
+
+	t.Run("Extreme Case: the only market has the products", func(t *testing.T) {
+		summary := newBestPriceSummary([]entities.ProductSample{
+			{
+				Market:   nameTestMarket1,
+				Products: []entities.Product{{Price: entities.Price{DiscountPrice: 300}}},
+			},
+			{
+				Market: nameTestMarket2,
+			},
+		})
+
+		if assert.NotNil(t, summary) {
+			assert.Equal(t, 0, summary.Spread)
+			assert.Equal(t, float64(0), summary.SpreadPercent)
+			assert.Equal(t, "testmarket1", summary.Market)
+		}
+	})
+}
@@ -4,23 +4,31 @@ import (
 	"github.com/labstack/echo/v4"
 
 	"github.com/MaKcm14/price-service/internal/entities/dto"
-	"github.com/MaKcm14/price-service/pkg/entities"
 )
 
 type (
 	CommonFilterAdapter interface {
-		FilterByMarkets(ctx echo.Context, request dto.ProductRequest) ([]entities.ProductSample, error)
+		FilterByMarkets(ctx echo.Context, request dto.ProductRequest) (dto.FilterResponse, error)
+		FilterByMarketsAsync(request dto.ProductRequest) (dto.Job, error)
 	}
 
 	PriceFilterAdapter interface {
-		FilterByPriceRange(ctx echo.Context, request dto.ProductRequest) ([]entities.ProductSample, error)
-		FilterByBestPrice(ctx echo.Context, request dto.ProductRequest) ([]entities.ProductSample, error)
-		FilterByExactPrice(ctx echo.Context, request dto.ProductRequest) ([]entities.ProductSample, error)
-		FilterByBestPriceAsync(ctx echo.Context, request dto.ProductRequest)
+		FilterByPriceRange(ctx echo.Context, request dto.ProductRequest) (dto.FilterResponse, error)
+		FilterByBestPrice(ctx echo.Context, request dto.ProductRequest) (dto.FilterResponse, error)
+		FilterByExactPrice(ctx echo.Context, request dto.ProductRequest) (dto.FilterResponse, error)
+		FilterByPriceRangeAsync(request dto.ProductRequest) (dto.Job, error)
+		FilterByBestPriceAsync(request dto.ProductRequest) (dto.Job, error)
+		FilterByExactPriceAsync(request dto.ProductRequest) (dto.Job, error)
+	}
+
+	JobsAdapter interface {
+		GetJob(id string) (dto.Job, error)
+		CancelJob(id string) (dto.Job, error)
 	}
 
 	Filter interface {
 		CommonFilterAdapter
 		PriceFilterAdapter
+		JobsAdapter
 	}
 )
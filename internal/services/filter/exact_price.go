@@ -0,0 +1,43 @@
+package filter
+
+import (
+	"math"
+	"sort"
+
+	"github.com/MaKcm14/price-service/pkg/entities"
+)
+
+// newPriceDeviation returns the deviation of the price from the exact price.
+func newPriceDeviation(price, exactPrice int) *entities.PriceDeviation {
+	deviation := entities.PriceDeviation{
+		Absolute: price - exactPrice,
+	}
+
+	if exactPrice != 0 {
+		deviation.Percent = math.Round(float64(deviation.Absolute)*10000/float64(exactPrice)) / 100
+	}
+
+	return &deviation
+}
+
+// rankByExactPrice sets the deviation from the exact price for every product and orders the products
+// by the distance to the exact price. The products without the price are placed at the end.
+func rankByExactPrice(products []entities.Product, exactPrice int) []entities.Product {
+	ranked := make([]entities.Product, 0, len(products))
+
+	for _, product := range products {
+		if product.Price.DiscountPrice != 0 {
+			product.Deviation = newPriceDeviation(product.Price.DiscountPrice, exactPrice)
+		}
+		ranked = append(ranked, product)
+	}
+
+	sort.SliceStable(ranked, func(i, j int) bool {
+		if ranked[i].Deviation == nil || ranked[j].Deviation == nil {
+			return ranked[j].Deviation == nil && ranked[i].Deviation != nil
+		}
+		return math.Abs(float64(ranked[i].Deviation.Absolute)) < math.Abs(float64(ranked[j].Deviation.Absolute))
+	})
+
+	return ranked
+}
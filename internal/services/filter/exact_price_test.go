@@ -0,0 +1,43 @@
+package filter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/MaKcm14/price-service/pkg/entities"
+)
+
+func TestRankByExactPricePositiveCases(t *testing.T) {
+	t.Run("Positive Case: the products are ranked by the distance to the exact price", func(t *testing.T) {
+		products := rankByExactPrice([]entities.Product{
+			{Name: "a", Price: entities.Price{DiscountPrice: 1100}},
+			{Name: "b", Price: entities.Price{}},
+			{Name: "c", Price: entities.Price{DiscountPrice: 980}},
+			{Name: "d", Price: entities.Price{DiscountPrice: 1020}},
+		}, 1000)
+
+		assert.Equal(t, []string{"c", "d", "a", "b"}, getTestProductsNames(products))
+	})
+
+	t.Run("Positive Case: the deviation is set for the products with the price", func(t *testing.T) {
+		products := rankByExactPrice([]entities.Product{
+			{Name: "a", Price: entities.Price{DiscountPrice: 950}},
+			{Name: "b", Price: entities.Price{}},
+		}, 1000)
+
+		assert.Equal(t, &entities.PriceDeviation{Absolute: -50, Percent: -5}, products[0].Deviation)
+		assert.Nil(t, products[1].Deviation)
+	})
+}
+
+func TestRankByExactPriceExtremeCases(t *testing.T) {
+	t.Run("Extreme Case: the equal distances keep the products' order", func(t *testing.T) {
+		products := rankByExactPrice([]entities.Product{
+			{Name: "a", Price: entities.Price{DiscountPrice: 1050}},
+			{Name: "b", Price: entities.Price{DiscountPrice: 950}},
+		}, 1000)
+
+		assert.Equal(t, []string{"a", "b"}, getTestProductsNames(products))
+	})
+}
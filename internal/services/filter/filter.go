@@ -1,8 +1,12 @@
 package filter
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"sync"
+	"time"
 
 	"github.com/labstack/echo/v4"
 
@@ -18,21 +22,118 @@ const (
 	commonFilter
 )
 
+const (
+	// defaultMarketDeadline is the time that is given to the market's api if its deadline wasn't set.
+	defaultMarketDeadline = time.Minute
+
+	// defaultPagesCap is the max amount of the pages that are got from the market for one request by default.
+	defaultPagesCap = 5
+)
+
 type filterType int
 
+// Opt defines the optional settings of the ProductsFilter.
+type Opt func(*ProductsFilter)
+
+// WithMarketsDeadlines sets the max time of waiting the response from every market's api.
+func WithMarketsDeadlines(deadlines map[entities.Market]time.Duration) Opt {
+	return func(p *ProductsFilter) {
+		for market, deadline := range deadlines {
+			p.deadlines[market] = deadline
+		}
+	}
+}
+
+// WithPagesCap sets the max amount of the pages that are got from every market
+// while the limit of the products is collecting.
+func WithPagesCap(pagesCap int) Opt {
+	return func(p *ProductsFilter) {
+		if pagesCap > 0 {
+			p.pagesCap = pagesCap
+		}
+	}
+}
+
+// WithJobsRetention sets the time that the finished async jobs are kept for the status polling.
+func WithJobsRetention(retention time.Duration) Opt {
+	return func(p *ProductsFilter) {
+		if retention > 0 {
+			p.jobs.retention = retention
+		}
+	}
+}
+
+// WithJobsDeadline sets the max time of processing every async job.
+func WithJobsDeadline(deadline time.Duration) Opt {
+	return func(p *ProductsFilter) {
+		if deadline > 0 {
+			p.runner.deadline = deadline
+		}
+	}
+}
+
+// WithJobsWorkers sets the amount of the async jobs that are processed at the same time.
+func WithJobsWorkers(workers int) Opt {
+	return func(p *ProductsFilter) {
+		if workers > 0 {
+			p.runner.workers = workers
+		}
+	}
+}
+
+// WithJobsQueueSize sets the max amount of the queued async jobs: the new jobs are rejected
+// while the queue is full.
+func WithJobsQueueSize(size int) Opt {
+	return func(p *ProductsFilter) {
+		if size > 0 {
+			p.runner.queueSize = size
+		}
+	}
+}
+
+// marketResult defines the result of the interaction with the concrete market's api.
+type marketResult struct {
+	sample    entities.ProductSample
+	err       error
+	elapsed   time.Duration
+	discarded int
+
+	// cursor is the position of the next products in the market's sample:
+	// it's nil if the market's sample is over.
+	cursor *dto.MarketCursor
+
+	// positions are the positions that follow every product of the sample:
+	// they're set only if the limit of the products was set.
+	positions []dto.MarketCursor
+}
+
 // ProductsFilter defines the logic of filtering the products.
 type ProductsFilter struct {
 	logger     *slog.Logger
 	marketsApi map[entities.Market]services.ApiInteractor
 	writer     services.AsyncWriter
+	deadlines  map[entities.Market]time.Duration
+	pagesCap   int
+	jobs       *jobRegistry
+	runner     *jobRunner
 }
 
-func New(log *slog.Logger, markets map[entities.Market]services.ApiInteractor, writer services.AsyncWriter) ProductsFilter {
-	return ProductsFilter{
+func New(log *slog.Logger, markets map[entities.Market]services.ApiInteractor, writer services.AsyncWriter, opts ...Opt) ProductsFilter {
+	filter := ProductsFilter{
 		logger:     log,
 		marketsApi: markets,
 		writer:     writer,
+		deadlines:  make(map[entities.Market]time.Duration),
+		pagesCap:   defaultPagesCap,
+		jobs:       newJobRegistry(defaultJobsRetention),
+		runner:     newJobRunner(defaultJobsDeadline),
+	}
+
+	for _, opt := range opts {
+		opt(&filter)
 	}
+
+	return filter
 }
 
 // getMarketApi returns the requested marketApi wrapped in the ApiInteractor.
@@ -46,90 +147,336 @@ func (p *ProductsFilter) getMarketApi(market entities.Market) (services.ApiInter
 	return marketApi, nil
 }
 
-// filter defines the main filter logic which defines the flow of control according to the set filter type.
-func (p *ProductsFilter) filter(ctx echo.Context, request dto.ProductRequest, serviceType string, filter filterType) ([]entities.ProductSample, error) {
-	var products = make([]entities.ProductSample, 0, 1000)
+// getMarketDeadline returns the max time of waiting the response from the market's api.
+func (p *ProductsFilter) getMarketDeadline(market entities.Market) time.Duration {
+	if deadline, flagExist := p.deadlines[market]; flagExist && deadline > 0 {
+		return deadline
+	}
+	return defaultMarketDeadline
+}
+
+// callMarketApi calls the market api's method according to the set filter type.
+func (p *ProductsFilter) callMarketApi(ctx context.Context, marketApi services.ApiInteractor,
+	request dto.ProductRequest, filter filterType) (entities.ProductSample, error) {
+	if filter == commonFilter {
+		return marketApi.GetProducts(ctx, request)
+	} else if filter == priceRangeFilter {
+		return marketApi.GetProductsWithPriceRange(ctx, request)
+	} else if filter == exactPriceFilter {
+		return marketApi.GetProductsWithExactPrice(ctx, request)
+	} else if filter == bestPriceFilter {
+		return marketApi.GetProductsWithBestPrice(ctx, request)
+	}
+	return entities.ProductSample{}, fmt.Errorf("unknown filter type: %d", filter)
+}
 
-	for _, market := range request.Markets {
-		var sample entities.ProductSample
-		var err error
+// filterMarket gets the products' sample from the concrete market in the time of the market's deadline.
+func (p *ProductsFilter) filterMarket(ctx context.Context, market entities.Market,
+	request dto.ProductRequest, filter filterType) marketResult {
+	marketApi, err := p.getMarketApi(market)
+
+	if err != nil {
+		return marketResult{err: err}
+	}
 
-		marketApi, err := p.getMarketApi(market)
+	marketCtx, cancel := context.WithTimeout(ctx, p.getMarketDeadline(market))
+	defer cancel()
 
-		if err != nil {
-			p.logger.Warn(fmt.Sprintf("error of the %v: %v", serviceType, err))
-			continue
-		}
+	res := make(chan marketResult, 1)
 
-		if filter == commonFilter {
-			sample, err = marketApi.GetProducts(ctx, request)
-		} else if filter == priceRangeFilter {
-			sample, err = marketApi.GetProductsWithPriceRange(ctx, request)
-		} else if filter == exactPriceFilter {
-			sample, err = marketApi.GetProductsWithExactPrice(ctx, request)
-		} else if filter == bestPriceFilter {
-			sample, err = marketApi.GetProductsWithBestPrice(ctx, request)
-		} else {
-			continue
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				res <- marketResult{err: fmt.Errorf("%w: %v", services.ErrMarketPanic, r)}
+			}
+		}()
+
+		res <- p.fetchMarket(marketCtx, marketApi, market, request, filter)
+	}()
+
+	select {
+	case result := <-res:
+		if result.err != nil && errors.Is(marketCtx.Err(), context.DeadlineExceeded) {
+			return marketResult{err: fmt.Errorf("%w: %v", services.ErrMarketTimeout, result.err)}
 		}
+		return result
+
+	case <-marketCtx.Done():
+		return marketResult{err: fmt.Errorf("%w: %v", services.ErrMarketTimeout, marketCtx.Err())}
+	}
+}
+
+// getMarketStatus returns the status of the interaction with the market according to its result.
+func (p *ProductsFilter) getMarketStatus(market entities.Market, result marketResult) entities.MarketStatus {
+	status := entities.MarketStatus{
+		Market:    market.String(),
+		Status:    entities.MarketStatusOK,
+		ElapsedMs: result.elapsed.Milliseconds(),
+		Discarded: result.discarded,
+	}
+
+	if result.err == nil {
+		return status
+	}
+
+	if errors.Is(result.err, services.ErrMarketTimeout) {
+		status.Status = entities.MarketStatusTimeout
+		status.ErrorCode = "market_timeout"
+	} else if errors.Is(result.err, services.ErrMarketApi) {
+		status.Status = entities.MarketStatusFailed
+		status.ErrorCode = "market_api"
+	} else if errors.Is(result.err, services.ErrMarketPanic) {
+		status.Status = entities.MarketStatusFailed
+		status.ErrorCode = "market_panic"
+	} else if errors.Is(result.err, services.ErrServiceBlocked) {
+		status.Status = entities.MarketStatusBlocked
+		status.ErrorCode = services.ErrorCode(result.err)
+	} else if errors.Is(result.err, services.ErrUnsupportedRequest) {
+		status.Status = entities.MarketStatusUnsupported
+		status.ErrorCode = services.ErrorCode(result.err)
+	} else {
+		status.Status = entities.MarketStatusFailed
+		status.ErrorCode = services.ErrorCode(result.err)
+	}
+
+	return status
+}
+
+// filter defines the main filter logic which defines the flow of control according to the set filter type.
+// Every market is requested concurrently and the samples are returned in the order of the request's markets
+// with the status of the interaction with every market.
+func (p *ProductsFilter) filter(ctx context.Context, request dto.ProductRequest, serviceType string, filter filterType) (dto.FilterResponse, error) {
+	var markets = getRequestMarkets(request)
+	var results = make([]marketResult, len(markets))
+	var wg sync.WaitGroup
 
-		if err != nil {
-			p.logger.Warn(fmt.Sprintf("error of the %v: %v", serviceType, err))
+	for i, market := range markets {
+		wg.Add(1)
+
+		go func(i int, market entities.Market) {
+			defer wg.Done()
+
+			start := time.Now()
+			results[i] = p.filterMarket(ctx, market, request, filter)
+			results[i].elapsed = time.Since(start)
+		}(i, market)
+	}
+	wg.Wait()
+
+	var response = dto.FilterResponse{
+		Samples:  make([]entities.ProductSample, 0, len(results)),
+		Statuses: make([]entities.MarketStatus, 0, len(results)),
+	}
+	var flagMergePage = request.FlagMerge && request.Limit != 0 && filter != bestPriceFilter
+
+	if flagMergePage {
+		response.Products, response.Cursor = mergePage(markets, results, request)
+	} else if request.Limit != 0 {
+		response.Cursor = getNextCursor(markets, results, request)
+	}
+
+	if response.Cursor != nil {
+		response.CursorKey = request.GetCursorKey()
+	}
+
+	for i, result := range results {
+		status := p.getMarketStatus(markets[i], result)
+		response.Statuses = append(response.Statuses, status)
+
+		if result.err != nil {
+			p.logger.Warn(fmt.Sprintf("error of the %v: %v", serviceType, result.err))
 			continue
 		}
+		response.Samples = append(response.Samples, result.sample)
+	}
 
-		products = append(products, sample)
+	if len(response.Samples) == 0 {
+		return response, services.ErrGettingProducts
 	}
 
-	if len(products) == 0 {
-		return nil, services.ErrGettingProducts
+	if filter == bestPriceFilter {
+		response.Products = getBestPriceProducts(response.Samples, request.Top)
+		response.BestPrice = newBestPriceSummary(response.Samples)
+	} else if filter == exactPriceFilter {
+		for i := range response.Samples {
+			response.Samples[i].Products = rankByExactPrice(response.Samples[i].Products, request.ExactPrice)
+		}
+
+		if flagMergePage {
+			response.Products = rankByExactPrice(response.Products, request.ExactPrice)
+		} else if request.FlagMerge {
+			response.Products = rankByExactPrice(mergeSamples(response.Samples, request.Sort), request.ExactPrice)
+		}
+	} else if request.FlagMerge && !flagMergePage {
+		response.Products = mergeSamples(response.Samples, request.Sort)
 	}
 
-	return products, nil
+	return response, nil
 }
 
 // FilterByMarkets defines the logic of the getting and processing the products' sample
 // from the markets' responses filtered only by markets and non-specified parameters.
-func (p ProductsFilter) FilterByMarkets(ctx echo.Context, request dto.ProductRequest) ([]entities.ProductSample, error) {
+func (p ProductsFilter) FilterByMarkets(ctx echo.Context, request dto.ProductRequest) (dto.FilterResponse, error) {
 	const serviceType = "filter.service.filter-by-markets"
-	return p.filter(ctx, request, serviceType, commonFilter)
+	return p.filter(ctx.Request().Context(), request, serviceType, commonFilter)
 }
 
 // FilterByPriceRange defines the logic of the getting and processing the products' sample
 // from the markets' responses constrained by the markets' filters and two boundaries of
 // the price range.
-func (p ProductsFilter) FilterByPriceRange(ctx echo.Context, request dto.ProductRequest) ([]entities.ProductSample, error) {
+func (p ProductsFilter) FilterByPriceRange(ctx echo.Context, request dto.ProductRequest) (dto.FilterResponse, error) {
 	const serviceType = "filter.service.filter-by-price-range"
-	return p.filter(ctx, request, serviceType, priceRangeFilter)
+	return p.filter(ctx.Request().Context(), request, serviceType, priceRangeFilter)
 }
 
 // FilterBestPrice defines the logic of the getting and processing the products' sample
-// from the markets' responses contrained by the markets' filters and the minimal price of the sample.
-func (p ProductsFilter) FilterByBestPrice(ctx echo.Context, request dto.ProductRequest) ([]entities.ProductSample, error) {
+// from the markets' responses contrained by the markets' filters and the minimal price of the sample:
+// the top cheapest products across all the markets are returned with the comparison of the markets' minimal prices.
+func (p ProductsFilter) FilterByBestPrice(ctx echo.Context, request dto.ProductRequest) (dto.FilterResponse, error) {
 	const serviceType = "filter.service.filter-by-best-price"
-	return p.filter(ctx, request, serviceType, bestPriceFilter)
+	return p.filter(ctx.Request().Context(), request, serviceType, bestPriceFilter)
 }
 
 // FilterByExactPrice defines the logic of the getting and processing the products' sample
 // from the markets' responses constrained by the markets' filters and the products that
-// have got the exactest prices to the client's price.
-func (p ProductsFilter) FilterByExactPrice(ctx echo.Context, request dto.ProductRequest) ([]entities.ProductSample, error) {
+// have got the exactest prices to the client's price: the products are ranked by the distance
+// to the client's price in the set tolerance window.
+func (p ProductsFilter) FilterByExactPrice(ctx echo.Context, request dto.ProductRequest) (dto.FilterResponse, error) {
 	const serviceType = "filter.service.filter-by-exact-price"
-	return p.filter(ctx, request, serviceType, bestPriceFilter)
+	return p.filter(ctx.Request().Context(), request, serviceType, exactPriceFilter)
+}
+
+// filterAsync creates the async job of the request and puts it in the queue of the background processing:
+// the result is sent through the kafka. It returns the queued job which state can be polled by its ID.
+func (p ProductsFilter) filterAsync(request dto.ProductRequest, serviceType string, filter filterType) (dto.Job, error) {
+	job, request, err := p.jobs.create(request, p.runner.getPriority(request.Priority))
+
+	if err != nil {
+		p.logger.Error(fmt.Sprintf("error of the %s: %s", serviceType, err))
+		return dto.Job{}, err
+	}
+
+	err = p.runner.run(job.ID, job.Priority, func(ctx context.Context) {
+		p.runJob(ctx, request, serviceType, filter)
+	})
+
+	if err != nil {
+		p.logger.Warn(fmt.Sprintf("error of the %s: job %s: %s", serviceType, job.ID, err))
+		p.jobs.remove(job.ID)
+		return dto.Job{}, err
+	}
+
+	return job, nil
+}
+
+// FilterByMarketsAsync defines the logic of the markets-filter in async format.
+func (p ProductsFilter) FilterByMarketsAsync(request dto.ProductRequest) (dto.Job, error) {
+	const serviceType = "filter.service.async-filter-by-markets"
+	return p.filterAsync(request, serviceType, commonFilter)
+}
+
+// FilterByPriceRangeAsync defines the logic of the price-range-filter in async format.
+func (p ProductsFilter) FilterByPriceRangeAsync(request dto.ProductRequest) (dto.Job, error) {
+	const serviceType = "filter.service.async-filter-by-price-range"
+	return p.filterAsync(request, serviceType, priceRangeFilter)
 }
 
 // FilterByBestPriceAsync defines the logic of getting and processing the products' sample in async format
 // according to the logic of the best-price-filter when the products which are with the minimal price will be returned
 // through the kafka.
-func (p ProductsFilter) FilterByBestPriceAsync(ctx echo.Context, request dto.ProductRequest) {
+func (p ProductsFilter) FilterByBestPriceAsync(request dto.ProductRequest) (dto.Job, error) {
 	const serviceType = "filter.service.async-filter-by-best-price"
+	return p.filterAsync(request, serviceType, bestPriceFilter)
+}
 
-	products, err := p.filter(ctx, request, serviceType, bestPriceFilter)
+// FilterByExactPriceAsync defines the logic of the exact-price-filter in async format.
+func (p ProductsFilter) FilterByExactPriceAsync(request dto.ProductRequest) (dto.Job, error) {
+	const serviceType = "filter.service.async-filter-by-exact-price"
+	return p.filterAsync(request, serviceType, exactPriceFilter)
+}
 
-	if err != nil {
-		p.logger.Error(fmt.Sprintf("error of the %s: %s", serviceType, err))
+// runJob processes the async job on the job's context: the result is sent through the kafka
+// and stored in the job's registry. If the job's context is done before the result is got
+// the job fails with the context's cause and the result isn't sent: the cancelled job sends
+// the terminal cancelled message instead of it. The job can't be cancelled after its context is
+// checked for the last time so the sent result is never followed by the job's cancellation.
+func (p ProductsFilter) runJob(ctx context.Context, request dto.ProductRequest, serviceType string, filter filterType) {
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	defer func() {
+		if r := recover(); r != nil {
+			p.logger.Error(fmt.Sprintf("error of the %s: job %s: %v: %v", serviceType, request.JobID, services.ErrJobPanic, r))
+			p.jobs.finish(request.JobID, dto.FilterResponse{}, fmt.Errorf("%w: %v", services.ErrJobPanic, r))
+		}
+	}()
+
+	if !p.jobs.start(request.JobID, cancel) {
+		return
+	}
+
+	var response dto.FilterResponse
+	var err error
+
+	if ctx.Err() == nil {
+		response, err = p.filter(ctx, request, serviceType, filter)
+	}
+	p.jobs.finishing(request.JobID)
+
+	if ctx.Err() != nil {
+		err = fmt.Errorf("%w: %v", context.Cause(ctx), ctx.Err())
+	}
+
+	if err != nil && errors.Is(err, services.ErrJobCancelled) {
+		p.logger.Info(fmt.Sprintf("%s: job %s was cancelled", serviceType, request.JobID))
+		p.writer.SendCancelledMessage(p.jobs.finish(request.JobID, dto.FilterResponse{}, err), request)
+		return
+	} else if err != nil {
+		p.logger.Error(fmt.Sprintf("error of the %s: job %s: %s", serviceType, request.JobID, err))
+		p.jobs.finish(request.JobID, dto.FilterResponse{}, err)
 		return
 	}
 
-	p.writer.SendProductsMessage(products, request)
+	p.writer.SendProductsMessage(response, request)
+	p.jobs.finish(request.JobID, response, nil)
+}
+
+// CancelJob cancels the queued or the running async job by the job's ID: the cancellation is propagated
+// into the markets' apis through the job's context and the terminal cancelled message is sent through the kafka.
+// The running job is cancelled asynchronously so its returned state can be still running.
+func (p ProductsFilter) CancelJob(id string) (dto.Job, error) {
+	const serviceType = "filter.service.job-cancelling"
+
+	job, request, err := p.jobs.cancel(id)
+
+	if err != nil {
+		p.logger.Warn(fmt.Sprintf("error of the %s: job %s: %s", serviceType, id, err))
+		return job, err
+	}
+
+	if job.State == dto.JobCancelled {
+		p.runner.remove(id)
+		p.logger.Info(fmt.Sprintf("%s: queued job %s was cancelled", serviceType, id))
+		p.writer.SendCancelledMessage(job, request)
+	}
+
+	return job, nil
+}
+
+// Shutdown stops accepting the new async jobs and waits for the running ones until the ctx is done:
+// the jobs that haven't finished in time are cancelled.
+func (p ProductsFilter) Shutdown(ctx context.Context) error {
+	const serviceType = "filter.service.shutdown"
+
+	if err := p.runner.shutdown(ctx); err != nil {
+		p.logger.Warn(fmt.Sprintf("error of the %s: %s", serviceType, err))
+		return err
+	}
+
+	return nil
+}
+
+// GetJob returns the async job's state and its result by the job's ID.
+func (p ProductsFilter) GetJob(id string) (dto.Job, error) {
+	return p.jobs.get(id)
 }
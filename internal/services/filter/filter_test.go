@@ -1,10 +1,16 @@
 package filter
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"log/slog"
 	"os"
 	"testing"
+	"time"
 
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
 
 	"github.com/MaKcm14/price-service/internal/entities/dto"
@@ -27,19 +33,19 @@ func (s *productsFilterTestSuite) SetupTest() {
 }
 
 func (s *productsFilterTestSuite) filterPositiveCaseOfFullMarketsHandlingSettings() {
-	s.mockTestMarket1.On("GetProducts", nil, dto.ProductRequest{
+	s.mockTestMarket1.On("GetProducts", mock.Anything, dto.ProductRequest{
 		Markets: []entities.Market{
 			testMarket1, testMarket2, testMarket3,
 		},
 	})
 
-	s.mockTestMarket2.On("GetProducts", nil, dto.ProductRequest{
+	s.mockTestMarket2.On("GetProducts", mock.Anything, dto.ProductRequest{
 		Markets: []entities.Market{
 			testMarket1, testMarket2, testMarket3,
 		},
 	})
 
-	s.mockTestMarket3.On("GetProducts", nil, dto.ProductRequest{
+	s.mockTestMarket3.On("GetProducts", mock.Anything, dto.ProductRequest{
 		Markets: []entities.Market{
 			testMarket1, testMarket2, testMarket3,
 		},
@@ -47,12 +53,12 @@ func (s *productsFilterTestSuite) filterPositiveCaseOfFullMarketsHandlingSetting
 }
 
 func (s *productsFilterTestSuite) filterExtremeCaseOfNotFullMarketsHandlingSettings() {
-	s.mockTestMarket1.On("GetProducts", nil, dto.ProductRequest{
+	s.mockTestMarket1.On("GetProducts", mock.Anything, dto.ProductRequest{
 		Markets: []entities.Market{
 			testMarket1, testMarket2,
 		}})
 
-	s.mockTestMarket2.On("GetProducts", nil, dto.ProductRequest{
+	s.mockTestMarket2.On("GetProducts", mock.Anything, dto.ProductRequest{
 		Markets: []entities.Market{
 			testMarket1, testMarket2,
 		}})
@@ -63,19 +69,19 @@ func (s *productsFilterTestSuite) filterFullNegativeMarketsApiInteractionSetting
 	s.mockTestMarket2.negativeInteraction = true
 	s.mockTestMarket3.negativeInteraction = true
 
-	s.mockTestMarket1.On("GetProducts", nil, dto.ProductRequest{
+	s.mockTestMarket1.On("GetProducts", mock.Anything, dto.ProductRequest{
 		Markets: []entities.Market{
 			testMarket1, testMarket2, testMarket3,
 		},
 	})
 
-	s.mockTestMarket2.On("GetProducts", nil, dto.ProductRequest{
+	s.mockTestMarket2.On("GetProducts", mock.Anything, dto.ProductRequest{
 		Markets: []entities.Market{
 			testMarket1, testMarket2, testMarket3,
 		},
 	})
 
-	s.mockTestMarket3.On("GetProducts", nil, dto.ProductRequest{
+	s.mockTestMarket3.On("GetProducts", mock.Anything, dto.ProductRequest{
 		Markets: []entities.Market{
 			testMarket1, testMarket2, testMarket3,
 		},
@@ -85,25 +91,70 @@ func (s *productsFilterTestSuite) filterFullNegativeMarketsApiInteractionSetting
 func (s *productsFilterTestSuite) filterPartialNegativeMarketsApiInteractionSettings() {
 	s.mockTestMarket1.negativeInteraction = true
 
-	s.mockTestMarket1.On("GetProducts", nil, dto.ProductRequest{
+	s.mockTestMarket1.On("GetProducts", mock.Anything, dto.ProductRequest{
 		Markets: []entities.Market{
 			testMarket1, testMarket2, testMarket3,
 		},
 	})
 
-	s.mockTestMarket2.On("GetProducts", nil, dto.ProductRequest{
+	s.mockTestMarket2.On("GetProducts", mock.Anything, dto.ProductRequest{
 		Markets: []entities.Market{
 			testMarket1, testMarket2, testMarket3,
 		},
 	})
 
-	s.mockTestMarket3.On("GetProducts", nil, dto.ProductRequest{
+	s.mockTestMarket3.On("GetProducts", mock.Anything, dto.ProductRequest{
 		Markets: []entities.Market{
 			testMarket1, testMarket2, testMarket3,
 		},
 	})
 }
 
+func (s *productsFilterTestSuite) filterExtremeCaseOfMarketDeadlineExceededSettings() {
+	s.mockTestMarket1.delay = time.Second
+
+	s.mockTestMarket1.On("GetProducts", mock.Anything, dto.ProductRequest{
+		Markets: []entities.Market{
+			testMarket1, testMarket2, testMarket3,
+		},
+	})
+
+	s.mockTestMarket2.On("GetProducts", mock.Anything, dto.ProductRequest{
+		Markets: []entities.Market{
+			testMarket1, testMarket2, testMarket3,
+		},
+	})
+
+	s.mockTestMarket3.On("GetProducts", mock.Anything, dto.ProductRequest{
+		Markets: []entities.Market{
+			testMarket1, testMarket2, testMarket3,
+		},
+	})
+}
+
+func (s *productsFilterTestSuite) filterPositiveCaseOfMarketsOrderSettings() {
+	s.mockTestMarket1.delay = time.Millisecond * 50
+	s.mockTestMarket2.delay = time.Millisecond * 25
+
+	s.mockTestMarket1.On("GetProducts", mock.Anything, dto.ProductRequest{
+		Markets: []entities.Market{
+			testMarket3, testMarket1, testMarket2,
+		},
+	})
+
+	s.mockTestMarket2.On("GetProducts", mock.Anything, dto.ProductRequest{
+		Markets: []entities.Market{
+			testMarket3, testMarket1, testMarket2,
+		},
+	})
+
+	s.mockTestMarket3.On("GetProducts", mock.Anything, dto.ProductRequest{
+		Markets: []entities.Market{
+			testMarket3, testMarket1, testMarket2,
+		},
+	})
+}
+
 func (s *productsFilterTestSuite) BeforeTest(suiteName, testName string) {
 	if testName == "TestFilterPositiveCaseOfFullMarketsHandling" {
 		s.filterPositiveCaseOfFullMarketsHandlingSettings()
@@ -116,6 +167,12 @@ func (s *productsFilterTestSuite) BeforeTest(suiteName, testName string) {
 
 	} else if testName == "TestFilterPartialNegativeMarketsApiInteraction" {
 		s.filterPartialNegativeMarketsApiInteractionSettings()
+
+	} else if testName == "TestFilterExtremeCaseOfMarketDeadlineExceeded" {
+		s.filterExtremeCaseOfMarketDeadlineExceededSettings()
+
+	} else if testName == "TestFilterPositiveCaseOfMarketsOrder" {
+		s.filterPositiveCaseOfMarketsOrderSettings()
 	}
 }
 
@@ -129,14 +186,14 @@ func (s *productsFilterTestSuite) TestFilterPositiveCaseOfFullMarketsHandling()
 		}, nil,
 	)
 
-	testProdSample, err := testFilterObj.filter(nil, dto.ProductRequest{
+	testProdSample, err := testFilterObj.filter(context.Background(), dto.ProductRequest{
 		Markets: []entities.Market{
 			testMarket1, testMarket2, testMarket3,
 		}}, "test_filter_service", commonFilter)
 
 	if s.NoError(err) {
 		count := 0
-		for _, testSample := range testProdSample {
+		for _, testSample := range testProdSample.Samples {
 			if testSample.Market == nameTestMarket1 {
 				count++
 			} else if testSample.Market == nameTestMarket2 {
@@ -167,13 +224,13 @@ func (s *productsFilterTestSuite) TestFilterExtremeCaseOfNotFullMarketsHandling(
 		}, nil,
 	)
 
-	testProdSample, err := testFilterObj.filter(nil, dto.ProductRequest{
+	testProdSample, err := testFilterObj.filter(context.Background(), dto.ProductRequest{
 		Markets: []entities.Market{
 			testMarket1, testMarket2,
 		}}, "test_filter_service", commonFilter)
 
 	if s.NoError(err) {
-		for _, testSample := range testProdSample {
+		for _, testSample := range testProdSample.Samples {
 			s.NotEqual(nameTestMarket3, testSample.Market)
 		}
 	}
@@ -197,14 +254,20 @@ func (s *productsFilterTestSuite) TestFilterFullNegativeMarketsApiInteraction()
 		}, nil,
 	)
 
-	testProdSample, err := testFilterObj.filter(nil, dto.ProductRequest{
+	testProdSample, err := testFilterObj.filter(context.Background(), dto.ProductRequest{
 		Markets: []entities.Market{
 			testMarket1, testMarket2, testMarket3,
 		}}, "test_filter_service", commonFilter)
 
 	if s.Error(err) {
 		s.Equal(services.ErrGettingProducts, err)
-		s.Equal(0, len(testProdSample))
+		s.Equal(0, len(testProdSample.Samples))
+		s.Equal(3, len(testProdSample.Statuses))
+
+		for _, testStatus := range testProdSample.Statuses {
+			s.Equal(entities.MarketStatusFailed, testStatus.Status)
+			s.NotEmpty(testStatus.ErrorCode)
+		}
 	}
 
 	s.mockTestMarket1.AssertExpectations(s.T())
@@ -222,14 +285,14 @@ func (s *productsFilterTestSuite) TestFilterPartialNegativeMarketsApiInteraction
 		}, nil,
 	)
 
-	testProdSample, err := testFilterObj.filter(nil, dto.ProductRequest{
+	testProdSample, err := testFilterObj.filter(context.Background(), dto.ProductRequest{
 		Markets: []entities.Market{
 			testMarket1, testMarket2, testMarket3,
 		}}, "test_filter_service", commonFilter)
 
 	if s.NoError(err) {
 		count := 0
-		for _, testSample := range testProdSample {
+		for _, testSample := range testProdSample.Samples {
 			if testSample.Market == nameTestMarket2 {
 				count++
 			} else if testSample.Market == nameTestMarket3 {
@@ -237,6 +300,12 @@ func (s *productsFilterTestSuite) TestFilterPartialNegativeMarketsApiInteraction
 			}
 		}
 		s.Equal(2, count)
+
+		if s.Equal(3, len(testProdSample.Statuses)) {
+			s.Equal(entities.MarketStatusFailed, testProdSample.Statuses[0].Status)
+			s.Equal(entities.MarketStatusOK, testProdSample.Statuses[1].Status)
+			s.Equal(entities.MarketStatusOK, testProdSample.Statuses[2].Status)
+		}
 	}
 
 	s.mockTestMarket1.AssertExpectations(s.T())
@@ -244,6 +313,124 @@ func (s *productsFilterTestSuite) TestFilterPartialNegativeMarketsApiInteraction
 	s.mockTestMarket3.AssertExpectations(s.T())
 }
 
+func (s *productsFilterTestSuite) TestFilterExtremeCaseOfMarketDeadlineExceeded() {
+	var testFilterObj = New(
+		slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError})),
+		map[entities.Market]services.ApiInteractor{
+			testMarket1: s.mockTestMarket1,
+			testMarket2: s.mockTestMarket2,
+			testMarket3: s.mockTestMarket3,
+		}, nil,
+		WithMarketsDeadlines(map[entities.Market]time.Duration{
+			testMarket1: time.Millisecond * 50,
+		}),
+	)
+
+	start := time.Now()
+
+	testProdSample, err := testFilterObj.filter(context.Background(), dto.ProductRequest{
+		Markets: []entities.Market{
+			testMarket1, testMarket2, testMarket3,
+		}}, "test_filter_service", commonFilter)
+
+	s.Less(time.Since(start), s.mockTestMarket1.delay)
+
+	if s.NoError(err) {
+		s.Equal(2, len(testProdSample.Samples))
+
+		for _, testSample := range testProdSample.Samples {
+			s.NotEqual(nameTestMarket1, testSample.Market)
+		}
+
+		if s.Equal(3, len(testProdSample.Statuses)) {
+			s.Equal(entities.MarketStatusTimeout, testProdSample.Statuses[0].Status)
+			s.Equal("market_timeout", testProdSample.Statuses[0].ErrorCode)
+			s.Equal(entities.MarketStatusOK, testProdSample.Statuses[1].Status)
+			s.Equal(entities.MarketStatusOK, testProdSample.Statuses[2].Status)
+		}
+	}
+
+	s.mockTestMarket1.AssertExpectations(s.T())
+	s.mockTestMarket2.AssertExpectations(s.T())
+	s.mockTestMarket3.AssertExpectations(s.T())
+}
+
+func (s *productsFilterTestSuite) TestFilterPositiveCaseOfMarketsOrder() {
+	var testFilterObj = New(
+		slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError})),
+		map[entities.Market]services.ApiInteractor{
+			testMarket1: s.mockTestMarket1,
+			testMarket2: s.mockTestMarket2,
+			testMarket3: s.mockTestMarket3,
+		}, nil,
+	)
+
+	testProdSample, err := testFilterObj.filter(context.Background(), dto.ProductRequest{
+		Markets: []entities.Market{
+			testMarket3, testMarket1, testMarket2,
+		}}, "test_filter_service", commonFilter)
+
+	if s.NoError(err) && s.Equal(3, len(testProdSample.Samples)) {
+		s.Equal(nameTestMarket3, testProdSample.Samples[0].Market)
+		s.Equal(nameTestMarket1, testProdSample.Samples[1].Market)
+		s.Equal(nameTestMarket2, testProdSample.Samples[2].Market)
+	}
+
+	s.mockTestMarket1.AssertExpectations(s.T())
+	s.mockTestMarket2.AssertExpectations(s.T())
+	s.mockTestMarket3.AssertExpectations(s.T())
+}
+
+func TestFilterMarketTimeoutError(t *testing.T) {
+	var mockTestMarket = newMarketApiMock(testMarket1, false)
+	mockTestMarket.delay = time.Second
+	mockTestMarket.On("GetProducts", mock.Anything, mock.Anything)
+
+	var testFilterObj = New(
+		slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError})),
+		map[entities.Market]services.ApiInteractor{
+			testMarket1: mockTestMarket,
+		}, nil,
+		WithMarketsDeadlines(map[entities.Market]time.Duration{
+			testMarket1: time.Millisecond * 20,
+		}),
+	)
+
+	result := testFilterObj.filterMarket(context.Background(), testMarket1, dto.ProductRequest{
+		Markets: []entities.Market{testMarket1},
+	}, commonFilter)
+
+	if result.err == nil || !errors.Is(result.err, services.ErrMarketTimeout) {
+		t.Errorf("filterMarket() error = %v, want %v", result.err, services.ErrMarketTimeout)
+	}
+}
+
 func TestProductsFilter(t *testing.T) {
 	suite.Run(t, new(productsFilterTestSuite))
 }
+
+func TestGetMarketStatusCases(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus entities.MarketStatusType
+		wantCode   string
+	}{
+		{"Positive Case: the market has responded", nil, entities.MarketStatusOK, ""},
+		{"Negative Case: the market has blocked the request", fmt.Errorf("test: %w", services.ErrServiceBlocked),
+			entities.MarketStatusBlocked, "service_blocked"},
+		{"Negative Case: the market doesn't support the request", fmt.Errorf("test: %w", services.ErrUnsupportedRequest),
+			entities.MarketStatusUnsupported, "unsupported_request"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var testFilterObj = ProductsFilter{}
+
+			status := testFilterObj.getMarketStatus(testMarket1, marketResult{err: tt.err})
+
+			assert.Equal(t, tt.wantStatus, status.Status)
+			assert.Equal(t, tt.wantCode, status.ErrorCode)
+		})
+	}
+}
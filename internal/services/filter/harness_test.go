@@ -1,11 +1,12 @@
 package filter
 
 import (
+	"context"
 	"fmt"
+	"time"
 
 	"github.com/MaKcm14/price-service/internal/entities/dto"
 	"github.com/MaKcm14/price-service/pkg/entities"
-	"github.com/labstack/echo/v4"
 	"github.com/stretchr/testify/mock"
 )
 
@@ -25,6 +26,8 @@ type marketApiMock struct {
 	mock.Mock
 	market              entities.Market
 	negativeInteraction bool
+	delay               time.Duration
+	flagPanic           bool
 }
 
 func newMarketApiMock(market entities.Market, negativeInteraction bool) *marketApiMock {
@@ -55,9 +58,18 @@ func (m *marketApiMock) getProductsForNegativeCaseInteraction() (entities.Produc
 	return entities.ProductSample{}, fmt.Errorf("test error of the market's api interaction")
 }
 
-func (m *marketApiMock) GetProducts(ctx echo.Context, request dto.ProductRequest) (entities.ProductSample, error) {
+func (m *marketApiMock) GetProducts(ctx context.Context, request dto.ProductRequest) (entities.ProductSample, error) {
+	if m.flagPanic {
+		panic("test panic of the market's api")
+	}
 	m.Called(ctx, request)
 
+	select {
+	case <-time.After(m.delay):
+	case <-ctx.Done():
+		return entities.ProductSample{}, ctx.Err()
+	}
+
 	if m.negativeInteraction {
 		return m.getProductsForNegativeCaseInteraction()
 	}
@@ -65,21 +77,21 @@ func (m *marketApiMock) GetProducts(ctx echo.Context, request dto.ProductRequest
 	return m.getProductsForPositiveCaseInteraction()
 }
 
-func (m *marketApiMock) GetProductsWithPriceRange(ctx echo.Context, request dto.ProductRequest) (entities.ProductSample, error) {
+func (m *marketApiMock) GetProductsWithPriceRange(ctx context.Context, request dto.ProductRequest) (entities.ProductSample, error) {
 	if m.negativeInteraction {
 		return m.getProductsForNegativeCaseInteraction()
 	}
 	return m.getProductsForPositiveCaseInteraction()
 }
 
-func (m *marketApiMock) GetProductsWithExactPrice(ctx echo.Context, request dto.ProductRequest) (entities.ProductSample, error) {
+func (m *marketApiMock) GetProductsWithExactPrice(ctx context.Context, request dto.ProductRequest) (entities.ProductSample, error) {
 	if m.negativeInteraction {
 		return m.getProductsForNegativeCaseInteraction()
 	}
 	return m.getProductsForPositiveCaseInteraction()
 }
 
-func (m *marketApiMock) GetProductsWithBestPrice(ctx echo.Context, request dto.ProductRequest) (entities.ProductSample, error) {
+func (m *marketApiMock) GetProductsWithBestPrice(ctx context.Context, request dto.ProductRequest) (entities.ProductSample, error) {
 	if m.negativeInteraction {
 		return m.getProductsForNegativeCaseInteraction()
 	}
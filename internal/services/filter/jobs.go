@@ -0,0 +1,195 @@
+package filter
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/MaKcm14/price-service/internal/entities/dto"
+	"github.com/MaKcm14/price-service/internal/services"
+)
+
+const (
+	// defaultJobsRetention is the time that the finished jobs are kept for the status polling.
+	defaultJobsRetention = time.Hour
+
+	// jobIDLength is the amount of the random bytes of the job's ID.
+	jobIDLength = 16
+)
+
+// jobEntry defines the registered job with the request it processes.
+type jobEntry struct {
+	job     dto.Job
+	request dto.ProductRequest
+
+	// cancel cancels the job's context: it's set only while the job is running.
+	cancel context.CancelCauseFunc
+
+	// flagFinishing is set when the job's result is got and it's being sent: the job can't be cancelled after it.
+	flagFinishing bool
+}
+
+// jobRegistry defines the storage of the async jobs' states.
+type jobRegistry struct {
+	mut       sync.RWMutex
+	jobs      map[string]*jobEntry
+	retention time.Duration
+	now       func() time.Time
+}
+
+func newJobRegistry(retention time.Duration) *jobRegistry {
+	return &jobRegistry{
+		jobs:      make(map[string]*jobEntry),
+		retention: retention,
+		now:       time.Now,
+	}
+}
+
+// create registers the new queued job of the request with the set priority.
+// The request with the job's ID is returned with the job.
+func (r *jobRegistry) create(request dto.ProductRequest, priority dto.JobPriority) (dto.Job, dto.ProductRequest, error) {
+	buf := make([]byte, jobIDLength)
+
+	if _, err := rand.Read(buf); err != nil {
+		return dto.Job{}, request, fmt.Errorf("%w: %v", services.ErrJobCreation, err)
+	}
+
+	r.mut.Lock()
+	defer r.mut.Unlock()
+
+	r.evictFinished()
+
+	entry := &jobEntry{
+		job: dto.Job{
+			ID:        hex.EncodeToString(buf),
+			State:     dto.JobQueued,
+			Priority:  priority,
+			CreatedAt: r.now(),
+		},
+		request: request,
+	}
+	entry.request.JobID = entry.job.ID
+	entry.request.Priority = priority
+	r.jobs[entry.job.ID] = entry
+
+	return entry.job, entry.request, nil
+}
+
+// evictFinished removes the jobs that were finished earlier than the retention time ago.
+func (r *jobRegistry) evictFinished() {
+	border := r.now().Add(-r.retention)
+
+	for id, entry := range r.jobs {
+		if entry.job.IsFinished() && entry.job.FinishedAt.Before(border) {
+			delete(r.jobs, id)
+		}
+	}
+}
+
+// start marks the queued job as running with the function that cancels its context.
+// It returns false if the job can't be started: for example, it was cancelled while it was queued.
+func (r *jobRegistry) start(id string, cancel context.CancelCauseFunc) bool {
+	r.mut.Lock()
+	defer r.mut.Unlock()
+
+	entry, flagExist := r.jobs[id]
+
+	if !flagExist || entry.job.State != dto.JobQueued {
+		return false
+	}
+	entry.job.State = dto.JobRunning
+	entry.job.StartedAt = r.now()
+	entry.cancel = cancel
+
+	return true
+}
+
+// finishing marks the running job as finishing: after it the job's cancellation is refused
+// so the job's context has to be checked for the last time after the marking.
+func (r *jobRegistry) finishing(id string) {
+	r.mut.Lock()
+	defer r.mut.Unlock()
+
+	if entry, flagExist := r.jobs[id]; flagExist {
+		entry.flagFinishing = true
+	}
+}
+
+// finish marks the job as succeeded with the result, as cancelled or as failed with the error.
+// It returns the finished job's copy.
+func (r *jobRegistry) finish(id string, response dto.FilterResponse, err error) dto.Job {
+	r.mut.Lock()
+	defer r.mut.Unlock()
+
+	entry, flagExist := r.jobs[id]
+
+	if !flagExist {
+		return dto.Job{}
+	}
+	entry.job.FinishedAt = r.now()
+	entry.cancel = nil
+
+	if err != nil && errors.Is(err, services.ErrJobCancelled) {
+		entry.job.State = dto.JobCancelled
+		entry.job.Err = err
+	} else if err != nil {
+		entry.job.State = dto.JobFailed
+		entry.job.Err = err
+	} else {
+		entry.job.State = dto.JobSucceeded
+		entry.job.Result = &response
+	}
+
+	return entry.job
+}
+
+// cancel cancels the job: the queued job is cancelled at once and the running one is cancelled through its context.
+// It returns the job's copy and the job's request.
+func (r *jobRegistry) cancel(id string) (dto.Job, dto.ProductRequest, error) {
+	r.mut.Lock()
+	defer r.mut.Unlock()
+
+	entry, flagExist := r.jobs[id]
+
+	if !flagExist {
+		return dto.Job{}, dto.ProductRequest{}, services.ErrJobNotFound
+	} else if entry.job.IsFinished() || entry.flagFinishing {
+		return entry.job, entry.request, services.ErrJobFinished
+	}
+
+	if entry.job.State == dto.JobQueued {
+		entry.job.State = dto.JobCancelled
+		entry.job.FinishedAt = r.now()
+		entry.job.Err = services.ErrJobCancelled
+	} else if entry.cancel != nil {
+		entry.cancel(services.ErrJobCancelled)
+	}
+
+	return entry.job, entry.request, nil
+}
+
+// remove removes the job that wasn't accepted for the processing.
+func (r *jobRegistry) remove(id string) {
+	r.mut.Lock()
+	defer r.mut.Unlock()
+
+	delete(r.jobs, id)
+}
+
+// get returns the job's copy by its ID.
+func (r *jobRegistry) get(id string) (dto.Job, error) {
+	r.mut.RLock()
+	defer r.mut.RUnlock()
+
+	entry, flagExist := r.jobs[id]
+
+	if !flagExist {
+		return dto.Job{}, services.ErrJobNotFound
+	}
+
+	return entry.job, nil
+}
@@ -0,0 +1,387 @@
+package filter
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/MaKcm14/price-service/internal/entities/dto"
+	"github.com/MaKcm14/price-service/internal/services"
+	"github.com/MaKcm14/price-service/pkg/entities"
+)
+
+// asyncWriterMock stores the requests of the sent messages.
+type asyncWriterMock struct {
+	mut       sync.Mutex
+	requests  []dto.ProductRequest
+	cancelled []dto.Job
+	flagPanic bool
+}
+
+func (w *asyncWriterMock) SendProductsMessage(response dto.FilterResponse, request dto.ProductRequest) {
+	w.mut.Lock()
+	defer w.mut.Unlock()
+
+	if w.flagPanic {
+		panic("test panic of the writer")
+	}
+
+	w.requests = append(w.requests, request)
+}
+
+func (w *asyncWriterMock) SendCancelledMessage(job dto.Job, request dto.ProductRequest) {
+	w.mut.Lock()
+	defer w.mut.Unlock()
+
+	w.cancelled = append(w.cancelled, job)
+	w.requests = append(w.requests, request)
+}
+
+func (w *asyncWriterMock) Close() {}
+
+// waitJob waits until the job is finished.
+func waitJob(t *testing.T, filter ProductsFilter, id string) dto.Job {
+	deadline := time.Now().Add(time.Second)
+
+	for time.Now().Before(deadline) {
+		if job, err := filter.GetJob(id); err == nil && job.IsFinished() {
+			return job
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	t.Fatal("the job hasn't been finished in time")
+	return dto.Job{}
+}
+
+func TestJobRegistryCases(t *testing.T) {
+	t.Run("Positive Case: the job goes through its states", func(t *testing.T) {
+		registry := newJobRegistry(time.Hour)
+
+		job, request, err := registry.create(dto.NewProductRequest(), dto.HighPriority)
+
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, dto.JobQueued, job.State)
+		assert.Equal(t, dto.HighPriority, job.Priority)
+		assert.Len(t, job.ID, 2*jobIDLength)
+		assert.Equal(t, job.ID, request.JobID)
+
+		assert.True(t, registry.start(job.ID, func(error) {}))
+		job, _ = registry.get(job.ID)
+		assert.Equal(t, dto.JobRunning, job.State)
+
+		registry.finish(job.ID, dto.FilterResponse{}, nil)
+		job, _ = registry.get(job.ID)
+		assert.Equal(t, dto.JobSucceeded, job.State)
+		assert.NotNil(t, job.Result)
+	})
+
+	t.Run("Negative Case: the job has failed", func(t *testing.T) {
+		registry := newJobRegistry(time.Hour)
+
+		job, _, _ := registry.create(dto.NewProductRequest(), dto.NormalPriority)
+		registry.finish(job.ID, dto.FilterResponse{}, services.ErrGettingProducts)
+		job, _ = registry.get(job.ID)
+
+		assert.Equal(t, dto.JobFailed, job.State)
+		assert.ErrorIs(t, job.Err, services.ErrGettingProducts)
+		assert.Nil(t, job.Result)
+	})
+
+	t.Run("Negative Case: the finishing job isn't cancelled", func(t *testing.T) {
+		registry := newJobRegistry(time.Hour)
+		flagCancelled := false
+
+		job, _, _ := registry.create(dto.NewProductRequest(), dto.NormalPriority)
+		registry.start(job.ID, func(error) { flagCancelled = true })
+		registry.finishing(job.ID)
+
+		_, _, err := registry.cancel(job.ID)
+
+		assert.ErrorIs(t, err, services.ErrJobFinished)
+		assert.False(t, flagCancelled)
+	})
+
+	t.Run("Extreme Case: the finished job is evicted after the retention", func(t *testing.T) {
+		registry := newJobRegistry(time.Minute)
+		now := time.Now()
+		registry.now = func() time.Time { return now }
+
+		finished, _, _ := registry.create(dto.NewProductRequest(), dto.NormalPriority)
+		registry.finish(finished.ID, dto.FilterResponse{}, nil)
+		queued, _, _ := registry.create(dto.NewProductRequest(), dto.NormalPriority)
+
+		now = now.Add(time.Hour)
+		registry.create(dto.NewProductRequest(), dto.NormalPriority)
+
+		_, err := registry.get(finished.ID)
+		assert.True(t, errors.Is(err, services.ErrJobNotFound))
+
+		_, err = registry.get(queued.ID)
+		assert.NoError(t, err)
+	})
+}
+
+func TestFilterByBestPriceAsyncCases(t *testing.T) {
+	t.Run("Positive Case: the result is sent with the job's ID", func(t *testing.T) {
+		market := newMarketApiMock(testMarket1, false)
+		writer := &asyncWriterMock{}
+
+		testFilterObj := New(slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError + 1})),
+			map[entities.Market]services.ApiInteractor{testMarket1: market}, writer)
+
+		request := dto.NewProductRequest()
+		request.Markets = []entities.Market{testMarket1}
+		request.Async = true
+
+		job, err := testFilterObj.FilterByBestPriceAsync(request)
+
+		if !assert.NoError(t, err) {
+			return
+		}
+		job = waitJob(t, testFilterObj, job.ID)
+
+		assert.Equal(t, dto.JobSucceeded, job.State)
+		assert.NotNil(t, job.Result)
+
+		writer.mut.Lock()
+		defer writer.mut.Unlock()
+
+		if assert.Len(t, writer.requests, 1) {
+			assert.Equal(t, job.ID, writer.requests[0].JobID)
+		}
+	})
+
+	t.Run("Negative Case: the job has failed", func(t *testing.T) {
+		market := newMarketApiMock(testMarket1, true)
+		writer := &asyncWriterMock{}
+
+		testFilterObj := New(slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError + 1})),
+			map[entities.Market]services.ApiInteractor{testMarket1: market}, writer)
+
+		request := dto.NewProductRequest()
+		request.Markets = []entities.Market{testMarket1}
+		request.Async = true
+
+		job, _ := testFilterObj.FilterByBestPriceAsync(request)
+		job = waitJob(t, testFilterObj, job.ID)
+
+		assert.Equal(t, dto.JobFailed, job.State)
+		assert.Empty(t, writer.requests)
+	})
+}
+
+func TestFilterAsyncJobContextCases(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError + 1}))
+
+	request := dto.NewProductRequest()
+	request.Markets = []entities.Market{testMarket1}
+	request.Async = true
+
+	t.Run("Negative Case: the job's deadline is exceeded", func(t *testing.T) {
+		market := newMarketApiMock(testMarket1, false)
+		market.delay = time.Second
+		market.On("GetProducts", mock.Anything, mock.Anything)
+		writer := &asyncWriterMock{}
+
+		testFilterObj := New(logger, map[entities.Market]services.ApiInteractor{testMarket1: market}, writer,
+			WithJobsDeadline(30*time.Millisecond))
+
+		job, err := testFilterObj.FilterByMarketsAsync(request)
+
+		if !assert.NoError(t, err) {
+			return
+		}
+		job = waitJob(t, testFilterObj, job.ID)
+
+		assert.Equal(t, dto.JobFailed, job.State)
+		assert.ErrorIs(t, job.Err, services.ErrJobDeadline)
+		assert.Empty(t, writer.requests)
+	})
+
+	t.Run("Negative Case: the job's panic is recovered", func(t *testing.T) {
+		market := newMarketApiMock(testMarket1, false)
+		market.On("GetProducts", mock.Anything, mock.Anything)
+		writer := &asyncWriterMock{flagPanic: true}
+
+		testFilterObj := New(logger, map[entities.Market]services.ApiInteractor{testMarket1: market}, writer)
+
+		job, _ := testFilterObj.FilterByMarketsAsync(request)
+		job = waitJob(t, testFilterObj, job.ID)
+
+		assert.Equal(t, dto.JobFailed, job.State)
+		assert.ErrorIs(t, job.Err, services.ErrJobPanic)
+	})
+
+	t.Run("Negative Case: the market's panic is recovered", func(t *testing.T) {
+		market := newMarketApiMock(testMarket1, false)
+		market.flagPanic = true
+		writer := &asyncWriterMock{}
+
+		testFilterObj := New(logger, map[entities.Market]services.ApiInteractor{testMarket1: market}, writer)
+
+		response, err := testFilterObj.filter(context.Background(), request, "test", commonFilter)
+
+		assert.ErrorIs(t, err, services.ErrGettingProducts)
+
+		if assert.Len(t, response.Statuses, 1) {
+			assert.Equal(t, "market_panic", response.Statuses[0].ErrorCode)
+		}
+	})
+
+	t.Run("Extreme Case: the jobs aren't accepted after the shutdown", func(t *testing.T) {
+		market := newMarketApiMock(testMarket1, false)
+		market.On("GetProducts", mock.Anything, mock.Anything)
+		writer := &asyncWriterMock{}
+
+		testFilterObj := New(logger, map[entities.Market]services.ApiInteractor{testMarket1: market}, writer)
+
+		job, _ := testFilterObj.FilterByMarketsAsync(request)
+
+		assert.NoError(t, testFilterObj.Shutdown(context.Background()))
+
+		job, _ = testFilterObj.GetJob(job.ID)
+		assert.Equal(t, dto.JobSucceeded, job.State)
+
+		_, err := testFilterObj.FilterByMarketsAsync(request)
+		assert.ErrorIs(t, err, services.ErrJobsStopped)
+	})
+}
+
+func TestCancelJobCases(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError + 1}))
+
+	request := dto.NewProductRequest()
+	request.Markets = []entities.Market{testMarket1}
+	request.Async = true
+	request.Headers["chat-id"] = "42"
+
+	t.Run("Positive Case: the running job is cancelled in the market's api", func(t *testing.T) {
+		called := make(chan context.Context, 1)
+
+		market := newMarketApiMock(testMarket1, false)
+		market.delay = time.Minute
+		market.On("GetProducts", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+			called <- args.Get(0).(context.Context)
+		})
+		writer := &asyncWriterMock{}
+
+		testFilterObj := New(logger, map[entities.Market]services.ApiInteractor{testMarket1: market}, writer)
+
+		job, _ := testFilterObj.FilterByMarketsAsync(request)
+
+		// the job is running when the market's api is called.
+		ctx := <-called
+
+		_, err := testFilterObj.CancelJob(job.ID)
+
+		if !assert.NoError(t, err) {
+			return
+		}
+		job = waitJob(t, testFilterObj, job.ID)
+
+		assert.Equal(t, dto.JobCancelled, job.State)
+		assert.ErrorIs(t, job.Err, services.ErrJobCancelled)
+
+		assert.ErrorIs(t, context.Cause(ctx), services.ErrJobCancelled)
+
+		writer.mut.Lock()
+		defer writer.mut.Unlock()
+
+		if assert.Len(t, writer.cancelled, 1) && assert.Len(t, writer.requests, 1) {
+			assert.Equal(t, job.ID, writer.cancelled[0].ID)
+			assert.Equal(t, job.ID, writer.requests[0].JobID)
+			assert.Equal(t, "42", writer.requests[0].Headers["chat-id"])
+		}
+	})
+
+	t.Run("Positive Case: the queued job is cancelled at once", func(t *testing.T) {
+		market := newMarketApiMock(testMarket1, false)
+		market.delay = time.Minute
+		market.On("GetProducts", mock.Anything, mock.Anything)
+		writer := &asyncWriterMock{}
+
+		testFilterObj := New(logger, map[entities.Market]services.ApiInteractor{testMarket1: market}, writer,
+			WithJobsWorkers(1))
+
+		running, _ := testFilterObj.FilterByMarketsAsync(request)
+		queued, _ := testFilterObj.FilterByMarketsAsync(request)
+
+		job, err := testFilterObj.CancelJob(queued.ID)
+
+		if assert.NoError(t, err) {
+			assert.Equal(t, dto.JobCancelled, job.State)
+		}
+
+		testFilterObj.CancelJob(running.ID)
+		waitJob(t, testFilterObj, running.ID)
+
+		writer.mut.Lock()
+		defer writer.mut.Unlock()
+
+		assert.Len(t, writer.cancelled, 2)
+	})
+
+	t.Run("Positive Case: the cancelled queued job frees its place in the queue", func(t *testing.T) {
+		called := make(chan context.Context, 1)
+
+		market := newMarketApiMock(testMarket1, false)
+		market.delay = time.Minute
+		market.On("GetProducts", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+			called <- args.Get(0).(context.Context)
+		})
+		writer := &asyncWriterMock{}
+
+		testFilterObj := New(logger, map[entities.Market]services.ApiInteractor{testMarket1: market}, writer,
+			WithJobsWorkers(1), WithJobsQueueSize(1))
+
+		running, _ := testFilterObj.FilterByMarketsAsync(request)
+		<-called
+
+		queued, err := testFilterObj.FilterByMarketsAsync(request)
+
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		_, err = testFilterObj.FilterByMarketsAsync(request)
+		assert.ErrorIs(t, err, services.ErrJobsQueueFull)
+
+		testFilterObj.CancelJob(queued.ID)
+
+		next, err := testFilterObj.FilterByMarketsAsync(request)
+		assert.NoError(t, err)
+
+		testFilterObj.CancelJob(next.ID)
+		testFilterObj.CancelJob(running.ID)
+		waitJob(t, testFilterObj, running.ID)
+	})
+
+	t.Run("Negative Case: the finished job isn't cancelled", func(t *testing.T) {
+		market := newMarketApiMock(testMarket1, false)
+		market.On("GetProducts", mock.Anything, mock.Anything)
+		writer := &asyncWriterMock{}
+
+		testFilterObj := New(logger, map[entities.Market]services.ApiInteractor{testMarket1: market}, writer)
+
+		job, _ := testFilterObj.FilterByMarketsAsync(request)
+		waitJob(t, testFilterObj, job.ID)
+
+		job, err := testFilterObj.CancelJob(job.ID)
+
+		assert.ErrorIs(t, err, services.ErrJobFinished)
+		assert.Equal(t, dto.JobSucceeded, job.State)
+
+		_, err = testFilterObj.CancelJob("unknown-job-id")
+		assert.ErrorIs(t, err, services.ErrJobNotFound)
+	})
+}
@@ -0,0 +1,136 @@
+package filter
+
+import (
+	"sort"
+
+	"github.com/MaKcm14/price-service/internal/entities/dto"
+	"github.com/MaKcm14/price-service/internal/services"
+	"github.com/MaKcm14/price-service/pkg/entities"
+)
+
+// rankedProduct defines the product with its position in the merged markets' samples.
+type rankedProduct struct {
+	product entities.Product
+	rank    int
+	market  int
+}
+
+// less defines the order of the products according to the sort type.
+// The markets' samples are already sorted by the markets themselves so the products which
+// can't be compared by the price are interleaved by their positions in the samples.
+// The equal products keep the order of their markets in the request.
+func (r rankedProduct) less(other rankedProduct, sortType dto.SortType) bool {
+	price, otherPrice := r.product.Price.DiscountPrice, other.product.Price.DiscountPrice
+
+	if (sortType == dto.PriceUpSort || sortType == dto.PriceDownSort) && price != otherPrice {
+		if price == 0 || otherPrice == 0 {
+			return otherPrice == 0
+		} else if sortType == dto.PriceUpSort {
+			return price < otherPrice
+		}
+		return price > otherPrice
+	}
+
+	if r.rank != other.rank {
+		return r.rank < other.rank
+	}
+	return r.market < other.market
+}
+
+// mergeSamples merges the products of the markets' samples in the one list ordered by the sort type.
+// Every product is tagged with its market.
+func mergeSamples(samples []entities.ProductSample, sortType dto.SortType) []entities.Product {
+	var count int
+
+	for _, sample := range samples {
+		count += len(sample.Products)
+	}
+
+	ranked := make([]rankedProduct, 0, count)
+
+	for i, sample := range samples {
+		for j, product := range sample.Products {
+			product.Market = sample.Market
+			ranked = append(ranked, rankedProduct{
+				product: product,
+				rank:    j,
+				market:  i,
+			})
+		}
+	}
+
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return ranked[i].less(ranked[j], sortType)
+	})
+
+	products := make([]entities.Product, 0, len(ranked))
+
+	for _, elem := range ranked {
+		products = append(products, elem.product)
+	}
+
+	return products
+}
+
+// mergePage returns the slice of the merged list of the markets' products in the limit of the request
+// and the cursor of the next slice. The slice is built by taking the best of the markets' next products
+// so every market's products are consumed in their order and the next slice continues the merged list
+// without the duplicates and the gaps. The markets' samples are cut to their consumed products.
+// The markets with the terminal errors are dropped from the cursor.
+func mergePage(markets []entities.Market, results []marketResult, request dto.ProductRequest) ([]entities.Product, dto.Cursor) {
+	var heads = make([]int, len(results))
+	var products = make([]entities.Product, 0, request.Limit)
+
+	for len(products) < request.Limit {
+		var best *rankedProduct
+
+		for i, result := range results {
+			if result.err != nil || heads[i] >= len(result.sample.Products) {
+				continue
+			}
+
+			product := rankedProduct{
+				product: result.sample.Products[heads[i]],
+				rank:    heads[i],
+				market:  i,
+			}
+
+			if best == nil || product.less(*best, request.Sort) {
+				best = &product
+			}
+		}
+
+		if best == nil {
+			break
+		}
+
+		best.product.Market = results[best.market].sample.Market
+		products = append(products, best.product)
+		heads[best.market]++
+	}
+
+	cursor := make(dto.Cursor)
+
+	for i := range results {
+		if results[i].err != nil && services.IsTerminal(results[i].err) {
+			continue
+		} else if results[i].err != nil {
+			cursor[markets[i]] = getStartPosition(markets[i], request)
+			continue
+		}
+
+		if consumed := heads[i]; consumed == 0 && len(results[i].sample.Products) != 0 {
+			cursor[markets[i]] = getStartPosition(markets[i], request)
+		} else if consumed < len(results[i].sample.Products) {
+			cursor[markets[i]] = results[i].positions[consumed-1]
+		} else if results[i].cursor != nil {
+			cursor[markets[i]] = *results[i].cursor
+		}
+		results[i].sample.Products = results[i].sample.Products[:heads[i]]
+	}
+
+	if len(cursor) == 0 {
+		return products, nil
+	}
+	return products, cursor
+}
@@ -0,0 +1,182 @@
+package filter
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/MaKcm14/price-service/internal/entities/dto"
+	"github.com/MaKcm14/price-service/internal/services"
+	"github.com/MaKcm14/price-service/pkg/entities"
+)
+
+func getTestMergeSamples() []entities.ProductSample {
+	return []entities.ProductSample{
+		{
+			Market: nameTestMarket1,
+			Products: []entities.Product{
+				{Name: "1-a", Price: entities.Price{DiscountPrice: 300}},
+				{Name: "1-b", Price: entities.Price{DiscountPrice: 100}},
+				{Name: "1-c", Price: entities.Price{}},
+			},
+		},
+		{
+			Market: nameTestMarket2,
+			Products: []entities.Product{
+				{Name: "2-a", Price: entities.Price{DiscountPrice: 100}},
+				{Name: "2-b", Price: entities.Price{DiscountPrice: 200}},
+			},
+		},
+	}
+}
+
+func getTestProductsNames(products []entities.Product) []string {
+	names := make([]string, 0, len(products))
+
+	for _, product := range products {
+		names = append(names, product.Name)
+	}
+
+	return names
+}
+
+func TestMergeSamplesPositiveCases(t *testing.T) {
+	t.Run("Positive Case: the price up sort with the stable tie-breaking", func(t *testing.T) {
+		products := mergeSamples(getTestMergeSamples(), dto.PriceUpSort)
+
+		assert.Equal(t, []string{"2-a", "1-b", "2-b", "1-a", "1-c"}, getTestProductsNames(products))
+	})
+
+	t.Run("Positive Case: the price down sort", func(t *testing.T) {
+		products := mergeSamples(getTestMergeSamples(), dto.PriceDownSort)
+
+		assert.Equal(t, []string{"1-a", "2-b", "2-a", "1-b", "1-c"}, getTestProductsNames(products))
+	})
+
+	t.Run("Positive Case: the popular sort interleaves the samples", func(t *testing.T) {
+		products := mergeSamples(getTestMergeSamples(), dto.PopularSort)
+
+		assert.Equal(t, []string{"1-a", "2-a", "1-b", "2-b", "1-c"}, getTestProductsNames(products))
+	})
+
+	t.Run("Positive Case: the products are tagged with the markets", func(t *testing.T) {
+		products := mergeSamples(getTestMergeSamples(), dto.PriceUpSort)
+
+		assert.Equal(t, nameTestMarket2, products[0].Market)
+		assert.Equal(t, nameTestMarket1, products[1].Market)
+	})
+}
+
+func TestMergeSamplesExtremeCases(t *testing.T) {
+	t.Run("Extreme Case: there aren't any samples", func(t *testing.T) {
+		products := mergeSamples(nil, dto.PriceUpSort)
+
+		assert.Equal(t, 0, len(products))
+	})
+}
+
+func getTestMergePageResults() []marketResult {
+	return []marketResult{
+		{
+			sample: entities.ProductSample{
+				Market: nameTestMarket1,
+				Products: []entities.Product{
+					{Name: "1-a", Price: entities.Price{DiscountPrice: 100}},
+					{Name: "1-b", Price: entities.Price{DiscountPrice: 300}},
+					{Name: "1-c", Price: entities.Price{DiscountPrice: 500}},
+				},
+			},
+			positions: []dto.MarketCursor{{Page: 1, Offset: 1}, {Page: 2}, {Page: 2, Offset: 1}},
+			cursor:    &dto.MarketCursor{Page: 3},
+		},
+		{
+			sample: entities.ProductSample{
+				Market: nameTestMarket2,
+				Products: []entities.Product{
+					{Name: "2-a", Price: entities.Price{DiscountPrice: 200}},
+					{Name: "2-b", Price: entities.Price{DiscountPrice: 400}},
+				},
+			},
+			positions: []dto.MarketCursor{{Page: 1, Offset: 1}, {Page: 2}},
+		},
+	}
+}
+
+func TestMergePagePositiveCases(t *testing.T) {
+	markets := []entities.Market{testMarket1, testMarket2}
+
+	t.Run("Positive Case: the slice is cut to the limit", func(t *testing.T) {
+		results := getTestMergePageResults()
+
+		products, cursor := mergePage(markets, results, dto.ProductRequest{Sort: dto.PriceUpSort, Limit: 3, Sample: 1})
+
+		assert.Equal(t, []string{"1-a", "2-a", "1-b"}, getTestProductsNames(products))
+		assert.Equal(t, dto.Cursor{
+			testMarket1: {Page: 2},
+			testMarket2: {Page: 1, Offset: 1},
+		}, cursor)
+		assert.Equal(t, []string{"1-a", "1-b"}, getTestProductsNames(results[0].sample.Products))
+	})
+
+	t.Run("Positive Case: the over market's sample is absent in the cursor", func(t *testing.T) {
+		results := getTestMergePageResults()
+
+		products, cursor := mergePage(markets, results, dto.ProductRequest{Sort: dto.PriceUpSort, Limit: 4, Sample: 1})
+
+		assert.Equal(t, []string{"1-a", "2-a", "1-b", "2-b"}, getTestProductsNames(products))
+		assert.Equal(t, dto.Cursor{testMarket1: {Page: 2}}, cursor)
+	})
+
+	t.Run("Positive Case: the unconsumed market keeps its start position", func(t *testing.T) {
+		results := getTestMergePageResults()
+
+		_, cursor := mergePage(markets, results, dto.ProductRequest{
+			Sort:   dto.PriceUpSort,
+			Limit:  1,
+			Sample: 1,
+			Cursor: dto.Cursor{testMarket1: {Page: 1}, testMarket2: {Page: 4, Offset: 2}},
+		})
+
+		assert.Equal(t, dto.Cursor{
+			testMarket1: {Page: 1, Offset: 1},
+			testMarket2: {Page: 4, Offset: 2},
+		}, cursor)
+	})
+}
+
+func TestMergePageExtremeCases(t *testing.T) {
+	markets := []entities.Market{testMarket1, testMarket2}
+
+	t.Run("Extreme Case: all the markets' samples are over", func(t *testing.T) {
+		results := getTestMergePageResults()
+		results[0].cursor = nil
+
+		products, cursor := mergePage(markets, results, dto.ProductRequest{Sort: dto.PriceUpSort, Limit: 10, Sample: 1})
+
+		assert.Len(t, products, 5)
+		assert.Nil(t, cursor)
+	})
+
+	t.Run("Extreme Case: the failed market is requested again", func(t *testing.T) {
+		results := getTestMergePageResults()
+		results[1] = marketResult{err: fmt.Errorf("test error")}
+
+		products, cursor := mergePage(markets, results, dto.ProductRequest{Sort: dto.PriceUpSort, Limit: 10, Sample: 1})
+
+		assert.Equal(t, []string{"1-a", "1-b", "1-c"}, getTestProductsNames(products))
+		assert.Equal(t, dto.Cursor{
+			testMarket1: {Page: 3},
+			testMarket2: {Page: 1},
+		}, cursor)
+	})
+
+	t.Run("Extreme Case: the market with the terminal error is dropped", func(t *testing.T) {
+		results := getTestMergePageResults()
+		results[1] = marketResult{err: fmt.Errorf("test error: %w", services.ErrUnsupportedRequest)}
+
+		_, cursor := mergePage(markets, results, dto.ProductRequest{Sort: dto.PriceUpSort, Limit: 10, Sample: 1})
+
+		assert.Equal(t, dto.Cursor{testMarket1: {Page: 3}}, cursor)
+	})
+}
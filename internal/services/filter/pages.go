@@ -0,0 +1,206 @@
+package filter
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/MaKcm14/price-service/internal/entities/dto"
+	"github.com/MaKcm14/price-service/internal/services"
+	"github.com/MaKcm14/price-service/pkg/entities"
+)
+
+// getRequestMarkets returns the markets that must be requested: if the request continues
+// the previous one only the markets which samples aren't over are requested.
+func getRequestMarkets(request dto.ProductRequest) []entities.Market {
+	if request.Cursor == nil {
+		return request.Markets
+	}
+
+	markets := make([]entities.Market, 0, len(request.Markets))
+
+	for _, market := range request.Markets {
+		if _, flagExist := request.Cursor[market]; flagExist {
+			markets = append(markets, market)
+		}
+	}
+
+	return markets
+}
+
+// getStartPosition returns the position in the market's sample that the request starts with.
+func getStartPosition(market entities.Market, request dto.ProductRequest) dto.MarketCursor {
+	if cursor, flagExist := request.Cursor[market]; flagExist {
+		return cursor
+	}
+	return dto.MarketCursor{Page: request.Sample}
+}
+
+// getNextCursor returns the positions of the next products in the markets' samples:
+// the markets that have failed transiently are requested again from their start positions
+// while the markets with the terminal errors are dropped.
+func getNextCursor(markets []entities.Market, results []marketResult, request dto.ProductRequest) dto.Cursor {
+	cursor := make(dto.Cursor)
+
+	for i, result := range results {
+		if result.err != nil && services.IsTerminal(result.err) {
+			continue
+		} else if result.err != nil {
+			cursor[markets[i]] = getStartPosition(markets[i], request)
+		} else if result.cursor != nil {
+			cursor[markets[i]] = *result.cursor
+		}
+	}
+
+	if len(cursor) == 0 {
+		return nil
+	}
+	return cursor
+}
+
+// getPagesConcurrency returns the amount of the pages that can be got from the market simultaneously.
+func getPagesConcurrency(marketApi services.ApiInteractor, request dto.ProductRequest) int {
+	if limiter, flagCheck := marketApi.(services.PagesLimiter); flagCheck {
+		if concurrency := limiter.GetPagesConcurrency(request); concurrency > 0 {
+			return concurrency
+		}
+	}
+	return 1
+}
+
+// fetchMarket gets the market's products that fit the price bounds of the filter.
+// The markets' apis return the whole pages: the only page's sample is cut to the amount of the request
+// here while the collected pages are cut by the positions of their products.
+func (p *ProductsFilter) fetchMarket(ctx context.Context, marketApi services.ApiInteractor,
+	market entities.Market, request dto.ProductRequest, filter filterType) marketResult {
+	if request.Limit != 0 {
+		return p.collectPages(ctx, marketApi, market, request, filter)
+	}
+
+	sample, err := p.callMarketApi(ctx, marketApi, request, filter)
+
+	if err != nil {
+		return marketResult{err: err}
+	}
+
+	result := marketResult{sample: sample}
+
+	if bounds, flagBounds := getPriceBounds(request, filter); flagBounds {
+		result.sample.Products, result.discarded = enforcePriceBounds(sample.Products, bounds)
+	}
+
+	if request.Amount > 0 {
+		result.sample.Products = result.sample.Products[:min(request.Amount, len(result.sample.Products))]
+	}
+
+	return result
+}
+
+// collectPages walks the consecutive pages of the market's sample until the limit of the products
+// that fit the price bounds of the filter is collected or the pages' cap is reached.
+// The pages are got in the batches of the market's pages concurrency.
+func (p *ProductsFilter) collectPages(ctx context.Context, marketApi services.ApiInteractor,
+	market entities.Market, request dto.ProductRequest, filter filterType) marketResult {
+	var result marketResult
+	var products = make([]entities.Product, 0, request.Limit)
+	var positions = make([]dto.MarketCursor, 0, request.Limit)
+	var start = getStartPosition(market, request)
+	var page = start.Page
+	var concurrency = getPagesConcurrency(marketApi, request)
+	var gotPages int
+
+	bounds, flagBounds := getPriceBounds(request, filter)
+
+	for fetched := 0; fetched < p.pagesCap; fetched += concurrency {
+		batch := make([]marketResult, min(concurrency, p.pagesCap-fetched))
+		wg := sync.WaitGroup{}
+
+		for i := range batch {
+			wg.Add(1)
+
+			go func(i int) {
+				defer wg.Done()
+				defer func() {
+					if r := recover(); r != nil {
+						batch[i] = marketResult{err: fmt.Errorf("%w: %v", services.ErrMarketPanic, r)}
+					}
+				}()
+
+				pageRequest := request
+				pageRequest.Sample = page + i
+
+				sample, err := p.callMarketApi(ctx, marketApi, pageRequest, filter)
+				batch[i] = marketResult{sample: sample, err: err}
+			}(i)
+		}
+		wg.Wait()
+
+		for _, pageResult := range batch {
+			if pageResult.err != nil && gotPages == 0 {
+				return marketResult{err: pageResult.err}
+			} else if pageResult.err != nil {
+				result.sample.Products, result.positions = products, positions
+				result.cursor = &dto.MarketCursor{Page: page}
+				return result
+			}
+
+			if gotPages == 0 {
+				result.sample = pageResult.sample
+			} else {
+				result.sample.Rejected = mergeRejected(result.sample.Rejected, pageResult.sample.Rejected)
+			}
+			gotPages++
+
+			if len(pageResult.sample.Products) == 0 {
+				result.sample.Products, result.positions = products, positions
+				return result
+			}
+
+			if flagBounds {
+				var discarded int
+				pageResult.sample.Products, discarded = enforcePriceBounds(pageResult.sample.Products, bounds)
+				result.discarded += discarded
+			}
+
+			var offset int
+
+			if page == start.Page {
+				offset = min(start.Offset, len(pageResult.sample.Products))
+			}
+
+			for j := offset; j < len(pageResult.sample.Products); j++ {
+				if j+1 < len(pageResult.sample.Products) {
+					positions = append(positions, dto.MarketCursor{Page: page, Offset: j + 1})
+				} else {
+					positions = append(positions, dto.MarketCursor{Page: page + 1})
+				}
+			}
+			products = append(products, pageResult.sample.Products[offset:]...)
+			page++
+
+			if len(products) >= request.Limit {
+				break
+			}
+		}
+
+		if len(products) >= request.Limit {
+			break
+		}
+	}
+
+	result.sample.Products, result.positions = products, positions
+	result.cursor = &dto.MarketCursor{Page: page}
+
+	return result
+}
+
+// mergeRejected adds the amounts of the page's rejected products to the sample's ones.
+func mergeRejected(rejected, pageRejected map[string]int) map[string]int {
+	for reason, count := range pageRejected {
+		if rejected == nil {
+			rejected = make(map[string]int, len(pageRejected))
+		}
+		rejected[reason] += count
+	}
+	return rejected
+}
@@ -0,0 +1,297 @@
+package filter
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/MaKcm14/price-service/internal/entities/dto"
+	"github.com/MaKcm14/price-service/internal/services"
+	"github.com/MaKcm14/price-service/pkg/entities"
+)
+
+// pagesApiMock defines the market's api that returns the sample page by page.
+type pagesApiMock struct {
+	mut         sync.Mutex
+	pages       map[int][]entities.Product
+	failedPage  int
+	panicPage   int
+	concurrency int
+	requested   []int
+}
+
+func (m *pagesApiMock) getPage(request dto.ProductRequest) (entities.ProductSample, error) {
+	m.mut.Lock()
+	m.requested = append(m.requested, request.Sample)
+	m.mut.Unlock()
+
+	if request.Sample == m.panicPage {
+		panic(fmt.Sprintf("test panic of the page %d", request.Sample))
+	} else if request.Sample == m.failedPage {
+		return entities.ProductSample{}, fmt.Errorf("test error of the page %d", request.Sample)
+	}
+
+	return entities.ProductSample{
+		Products: m.pages[request.Sample],
+		Market:   nameTestMarket1,
+	}, nil
+}
+
+func (m *pagesApiMock) GetProducts(ctx context.Context, request dto.ProductRequest) (entities.ProductSample, error) {
+	return m.getPage(request)
+}
+
+func (m *pagesApiMock) GetProductsWithPriceRange(ctx context.Context, request dto.ProductRequest) (entities.ProductSample, error) {
+	return m.getPage(request)
+}
+
+func (m *pagesApiMock) GetProductsWithExactPrice(ctx context.Context, request dto.ProductRequest) (entities.ProductSample, error) {
+	return m.getPage(request)
+}
+
+func (m *pagesApiMock) GetProductsWithBestPrice(ctx context.Context, request dto.ProductRequest) (entities.ProductSample, error) {
+	return m.getPage(request)
+}
+
+func (m *pagesApiMock) GetPagesConcurrency(request dto.ProductRequest) int {
+	return m.concurrency
+}
+
+func getTestPages(pagesCount, pageSize int) map[int][]entities.Product {
+	pages := make(map[int][]entities.Product, pagesCount)
+
+	for page := 1; page <= pagesCount; page++ {
+		for i := 0; i < pageSize; i++ {
+			pages[page] = append(pages[page], entities.Product{
+				Name:  fmt.Sprintf("%d-%d", page, i),
+				Price: entities.Price{DiscountPrice: page*100 + i},
+			})
+		}
+	}
+
+	return pages
+}
+
+func getTestPagesFilter(pagesCap int) ProductsFilter {
+	return New(
+		slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError})),
+		map[entities.Market]services.ApiInteractor{}, nil,
+		WithPagesCap(pagesCap),
+	)
+}
+
+func TestGetNextCursorCases(t *testing.T) {
+	markets := []entities.Market{testMarket1, testMarket2, testMarket3}
+	request := dto.ProductRequest{Sample: 1, Limit: 10, Cursor: dto.Cursor{
+		testMarket1: {Page: 2},
+		testMarket2: {Page: 3, Offset: 4},
+		testMarket3: {Page: 5},
+	}}
+
+	results := []marketResult{
+		{cursor: &dto.MarketCursor{Page: 4}},
+		{err: fmt.Errorf("test error: %w", services.ErrMarketTimeout)},
+		{err: fmt.Errorf("test error: %w", services.ErrUnsupportedRequest)},
+	}
+
+	assert.Equal(t, dto.Cursor{
+		testMarket1: {Page: 4},
+		testMarket2: {Page: 3, Offset: 4},
+	}, getNextCursor(markets, results, request))
+}
+
+func TestCollectPagesPositiveCases(t *testing.T) {
+	t.Run("Positive Case: the limit is collected across the pages", func(t *testing.T) {
+		testFilterObj := getTestPagesFilter(5)
+		marketApi := &pagesApiMock{pages: getTestPages(5, 10), concurrency: 1}
+
+		result := testFilterObj.collectPages(context.Background(), marketApi, testMarket1,
+			dto.ProductRequest{Sample: 1, Limit: 25}, commonFilter)
+
+		assert.NoError(t, result.err)
+		assert.Len(t, result.sample.Products, 30)
+		assert.Equal(t, &dto.MarketCursor{Page: 4}, result.cursor)
+		assert.Equal(t, []int{1, 2, 3}, marketApi.requested)
+	})
+
+	t.Run("Positive Case: the collecting continues from the cursor", func(t *testing.T) {
+		testFilterObj := getTestPagesFilter(5)
+		marketApi := &pagesApiMock{pages: getTestPages(5, 10), concurrency: 1}
+
+		result := testFilterObj.collectPages(context.Background(), marketApi, testMarket1,
+			dto.ProductRequest{Sample: 1, Limit: 10, Cursor: dto.Cursor{testMarket1: {Page: 3}}}, commonFilter)
+
+		assert.NoError(t, result.err)
+		assert.Equal(t, "3-0", result.sample.Products[0].Name)
+		assert.Equal(t, &dto.MarketCursor{Page: 4}, result.cursor)
+	})
+
+	t.Run("Positive Case: the returned products of the cursor's page are skipped", func(t *testing.T) {
+		testFilterObj := getTestPagesFilter(5)
+		marketApi := &pagesApiMock{pages: getTestPages(5, 10), concurrency: 1}
+
+		result := testFilterObj.collectPages(context.Background(), marketApi, testMarket1,
+			dto.ProductRequest{Sample: 1, Limit: 10, Cursor: dto.Cursor{testMarket1: {Page: 2, Offset: 8}}}, commonFilter)
+
+		assert.NoError(t, result.err)
+		assert.Equal(t, []string{"2-8", "2-9"}, getTestProductsNames(result.sample.Products[:2]))
+		assert.Len(t, result.positions, len(result.sample.Products))
+		assert.Equal(t, dto.MarketCursor{Page: 2, Offset: 9}, result.positions[0])
+		assert.Equal(t, dto.MarketCursor{Page: 3}, result.positions[1])
+	})
+
+	t.Run("Positive Case: the pages are got concurrently", func(t *testing.T) {
+		testFilterObj := getTestPagesFilter(5)
+		marketApi := &pagesApiMock{pages: getTestPages(5, 10), concurrency: 3}
+
+		result := testFilterObj.collectPages(context.Background(), marketApi, testMarket1,
+			dto.ProductRequest{Sample: 1, Limit: 15}, commonFilter)
+
+		assert.NoError(t, result.err)
+		assert.Len(t, result.sample.Products, 20)
+		assert.ElementsMatch(t, []int{1, 2, 3}, marketApi.requested)
+		assert.Equal(t, &dto.MarketCursor{Page: 3}, result.cursor)
+	})
+
+	t.Run("Positive Case: the products out of the bounds are discarded", func(t *testing.T) {
+		testFilterObj := getTestPagesFilter(5)
+		marketApi := &pagesApiMock{pages: getTestPages(5, 10), concurrency: 1}
+
+		result := testFilterObj.collectPages(context.Background(), marketApi, testMarket1,
+			dto.ProductRequest{Sample: 1, Limit: 10, PriceRange: dto.PriceRangeRequest{PriceDown: 105, PriceUp: 500}},
+			priceRangeFilter)
+
+		assert.NoError(t, result.err)
+		assert.Len(t, result.sample.Products, 15)
+		assert.Equal(t, 5, result.discarded)
+	})
+}
+
+func TestCollectPagesExtremeCases(t *testing.T) {
+	t.Run("Extreme Case: the market's sample is over", func(t *testing.T) {
+		testFilterObj := getTestPagesFilter(5)
+		marketApi := &pagesApiMock{pages: getTestPages(2, 10), concurrency: 1}
+
+		result := testFilterObj.collectPages(context.Background(), marketApi, testMarket1,
+			dto.ProductRequest{Sample: 1, Limit: 50}, commonFilter)
+
+		assert.NoError(t, result.err)
+		assert.Len(t, result.sample.Products, 20)
+		assert.Nil(t, result.cursor)
+	})
+
+	t.Run("Extreme Case: the pages' cap is reached", func(t *testing.T) {
+		testFilterObj := getTestPagesFilter(2)
+		marketApi := &pagesApiMock{pages: getTestPages(5, 10), concurrency: 1}
+
+		result := testFilterObj.collectPages(context.Background(), marketApi, testMarket1,
+			dto.ProductRequest{Sample: 1, Limit: 50}, commonFilter)
+
+		assert.NoError(t, result.err)
+		assert.Len(t, result.sample.Products, 20)
+		assert.Equal(t, &dto.MarketCursor{Page: 3}, result.cursor)
+	})
+
+	t.Run("Extreme Case: the later page is failed", func(t *testing.T) {
+		testFilterObj := getTestPagesFilter(5)
+		marketApi := &pagesApiMock{pages: getTestPages(5, 10), concurrency: 1, failedPage: 2}
+
+		result := testFilterObj.collectPages(context.Background(), marketApi, testMarket1,
+			dto.ProductRequest{Sample: 1, Limit: 50}, commonFilter)
+
+		assert.NoError(t, result.err)
+		assert.Len(t, result.sample.Products, 10)
+		assert.Equal(t, &dto.MarketCursor{Page: 2}, result.cursor)
+	})
+}
+
+func TestCollectPagesNegativeCases(t *testing.T) {
+	t.Run("Negative Case: the first page is failed", func(t *testing.T) {
+		testFilterObj := getTestPagesFilter(5)
+		marketApi := &pagesApiMock{pages: getTestPages(5, 10), concurrency: 1, failedPage: 1}
+
+		result := testFilterObj.collectPages(context.Background(), marketApi, testMarket1,
+			dto.ProductRequest{Sample: 1, Limit: 50}, commonFilter)
+
+		assert.Error(t, result.err)
+	})
+
+	t.Run("Negative Case: the market's panic on the page is recovered", func(t *testing.T) {
+		testFilterObj := getTestPagesFilter(5)
+		marketApi := &pagesApiMock{pages: getTestPages(5, 10), concurrency: 2, panicPage: 1}
+
+		result := testFilterObj.collectPages(context.Background(), marketApi, testMarket1,
+			dto.ProductRequest{Sample: 1, Limit: 50}, commonFilter)
+
+		assert.ErrorIs(t, result.err, services.ErrMarketPanic)
+	})
+
+	t.Run("Negative Case: the market's panic on the later page is recovered", func(t *testing.T) {
+		testFilterObj := getTestPagesFilter(5)
+		marketApi := &pagesApiMock{pages: getTestPages(5, 10), concurrency: 1, panicPage: 2}
+
+		result := testFilterObj.collectPages(context.Background(), marketApi, testMarket1,
+			dto.ProductRequest{Sample: 1, Limit: 50}, commonFilter)
+
+		assert.NoError(t, result.err)
+		assert.Len(t, result.sample.Products, 10)
+		assert.Equal(t, &dto.MarketCursor{Page: 2}, result.cursor)
+	})
+}
+
+func TestFetchMarketAmountCases(t *testing.T) {
+	t.Run("Positive Case: the only page is cut to the amount", func(t *testing.T) {
+		testFilterObj := getTestPagesFilter(5)
+		marketApi := &pagesApiMock{pages: getTestPages(1, 10), concurrency: 1}
+
+		result := testFilterObj.fetchMarket(context.Background(), marketApi, testMarket1,
+			dto.ProductRequest{Sample: 1, Amount: 4}, commonFilter)
+
+		assert.NoError(t, result.err)
+		assert.Equal(t, []string{"1-0", "1-1", "1-2", "1-3"}, getTestProductsNames(result.sample.Products))
+	})
+
+	t.Run("Positive Case: the cursor's hops return every product of the pages larger than the amount", func(t *testing.T) {
+		marketApi := &pagesApiMock{pages: getTestPages(2, 10), concurrency: 1}
+
+		testFilterObj := New(
+			slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError + 1})),
+			map[entities.Market]services.ApiInteractor{testMarket1: marketApi}, nil,
+			WithPagesCap(5),
+		)
+
+		request := dto.ProductRequest{
+			Sample:    1,
+			Amount:    4,
+			Limit:     4,
+			FlagMerge: true,
+			Markets:   []entities.Market{testMarket1},
+		}
+		names := make([]string, 0, 20)
+
+		for hop := 0; hop != 10 && (hop == 0 || request.Cursor != nil); hop++ {
+			response, err := testFilterObj.filter(context.Background(), request, "test", commonFilter)
+
+			if !assert.NoError(t, err) {
+				return
+			}
+			names = append(names, getTestProductsNames(response.Products)...)
+			request.Cursor = response.Cursor
+		}
+
+		want := make([]string, 0, 20)
+
+		for page := 1; page <= 2; page++ {
+			for i := 0; i < 10; i++ {
+				want = append(want, fmt.Sprintf("%d-%d", page, i))
+			}
+		}
+
+		assert.Equal(t, want, names)
+	})
+}
@@ -0,0 +1,32 @@
+package filter
+
+import (
+	"github.com/MaKcm14/price-service/internal/entities/dto"
+	"github.com/MaKcm14/price-service/pkg/entities"
+)
+
+// getPriceBounds returns the price bounds that the products of the filter must fit.
+// It returns false if the filter doesn't constrain the products' prices.
+func getPriceBounds(request dto.ProductRequest, filter filterType) (dto.PriceRangeRequest, bool) {
+	if filter == priceRangeFilter {
+		return request.PriceRange, true
+	} else if filter == exactPriceFilter {
+		return request.GetExactPriceRange(), true
+	}
+	return dto.PriceRangeRequest{}, false
+}
+
+// enforcePriceBounds removes the products which prices are out of the bounds or aren't set.
+// It returns the amount of the discarded products.
+func enforcePriceBounds(products []entities.Product, bounds dto.PriceRangeRequest) ([]entities.Product, int) {
+	filtered := make([]entities.Product, 0, len(products))
+
+	for _, product := range products {
+		if price := product.Price.DiscountPrice; price == 0 || price < bounds.PriceDown || price > bounds.PriceUp {
+			continue
+		}
+		filtered = append(filtered, product)
+	}
+
+	return filtered, len(products) - len(filtered)
+}
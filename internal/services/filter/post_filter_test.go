@@ -0,0 +1,67 @@
+package filter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/MaKcm14/price-service/internal/entities/dto"
+	"github.com/MaKcm14/price-service/pkg/entities"
+)
+
+func TestEnforcePriceBoundsPositiveCases(t *testing.T) {
+	t.Run("Positive Case: the products out of the bounds are discarded", func(t *testing.T) {
+		products, discarded := enforcePriceBounds([]entities.Product{
+			{Name: "a", Price: entities.Price{DiscountPrice: 999}},
+			{Name: "b", Price: entities.Price{DiscountPrice: 1000}},
+			{Name: "c", Price: entities.Price{DiscountPrice: 1500}},
+			{Name: "d", Price: entities.Price{DiscountPrice: 2000}},
+			{Name: "e", Price: entities.Price{DiscountPrice: 2001}},
+		}, dto.PriceRangeRequest{PriceDown: 1000, PriceUp: 2000})
+
+		assert.Equal(t, []string{"b", "c", "d"}, getTestProductsNames(products))
+		assert.Equal(t, 2, discarded)
+	})
+
+	t.Run("Positive Case: the products without the price are discarded", func(t *testing.T) {
+		products, discarded := enforcePriceBounds([]entities.Product{
+			{Name: "a", Price: entities.Price{}},
+			{Name: "b", Price: entities.Price{DiscountPrice: 100}},
+		}, dto.PriceRangeRequest{PriceDown: 0, PriceUp: 2000})
+
+		assert.Equal(t, []string{"b"}, getTestProductsNames(products))
+		assert.Equal(t, 1, discarded)
+	})
+}
+
+func TestGetPriceBoundsCases(t *testing.T) {
+	request := dto.ProductRequest{
+		PriceRange: dto.PriceRangeRequest{PriceDown: 100, PriceUp: 200},
+		ExactPrice: 1000,
+		Tolerance: dto.PriceToleranceRequest{
+			Value:     10,
+			Type:      dto.PercentTolerance,
+			Direction: dto.BothDirection,
+		},
+	}
+
+	t.Run("Positive Case: the price-range filter's bounds", func(t *testing.T) {
+		bounds, flagBounds := getPriceBounds(request, priceRangeFilter)
+
+		assert.True(t, flagBounds)
+		assert.Equal(t, dto.PriceRangeRequest{PriceDown: 100, PriceUp: 200}, bounds)
+	})
+
+	t.Run("Positive Case: the exact-price filter's bounds", func(t *testing.T) {
+		bounds, flagBounds := getPriceBounds(request, exactPriceFilter)
+
+		assert.True(t, flagBounds)
+		assert.Equal(t, dto.PriceRangeRequest{PriceDown: 900, PriceUp: 1100}, bounds)
+	})
+
+	t.Run("Extreme Case: the filter without the bounds", func(t *testing.T) {
+		_, flagBounds := getPriceBounds(request, commonFilter)
+
+		assert.False(t, flagBounds)
+	})
+}
@@ -0,0 +1,234 @@
+package filter
+
+import (
+	"context"
+	"expvar"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/MaKcm14/price-service/internal/entities/dto"
+	"github.com/MaKcm14/price-service/internal/services"
+)
+
+const (
+	// defaultJobsDeadline is the max time of processing the async job if its deadline wasn't set.
+	defaultJobsDeadline = 5 * time.Minute
+
+	// defaultJobsWorkers is the amount of the async jobs that are processed at the same time by default.
+	defaultJobsWorkers = 4
+
+	// defaultJobsQueueSize is the max amount of the queued async jobs by default.
+	defaultJobsQueueSize = 100
+)
+
+// jobsPriorities defines the order of the queues' processing: the jobs with the higher priority are taken first.
+var jobsPriorities = []dto.JobPriority{dto.HighPriority, dto.NormalPriority, dto.LowPriority}
+
+// jobsMetric contains the depth of the async jobs' queue and the time that the jobs wait in it.
+var jobsMetric = expvar.NewMap("async_jobs")
+
+// jobTask defines the queued async job.
+type jobTask struct {
+	id       string
+	task     func(ctx context.Context)
+	queuedAt time.Time
+}
+
+// jobRunner defines the pool of the workers that process the async jobs from the queue with the priorities.
+// Every job is run on its own context that isn't bound to the client's request and is cancelled only
+// by the job's deadline or by the runner's shutdown.
+type jobRunner struct {
+	mut         sync.Mutex
+	cond        *sync.Cond
+	once        sync.Once
+	wg          sync.WaitGroup
+	ctx         context.Context
+	cancel      context.CancelCauseFunc
+	queues      map[dto.JobPriority][]jobTask
+	queued      int
+	deadline    time.Duration
+	workers     int
+	queueSize   int
+	flagStopped bool
+}
+
+func newJobRunner(deadline time.Duration) *jobRunner {
+	ctx, cancel := context.WithCancelCause(context.Background())
+
+	runner := &jobRunner{
+		ctx:       ctx,
+		cancel:    cancel,
+		queues:    make(map[dto.JobPriority][]jobTask, len(jobsPriorities)),
+		deadline:  deadline,
+		workers:   defaultJobsWorkers,
+		queueSize: defaultJobsQueueSize,
+	}
+	runner.cond = sync.NewCond(&runner.mut)
+
+	return runner
+}
+
+// getPriority returns the known priority of the job: the unknown priority is considered as the normal one.
+func (r *jobRunner) getPriority(priority dto.JobPriority) dto.JobPriority {
+	for _, knownPriority := range jobsPriorities {
+		if priority == knownPriority {
+			return priority
+		}
+	}
+	return dto.NormalPriority
+}
+
+// run puts the task of the job with the id in the queue with the set priority: the task is run by the free worker
+// with the job's context. It returns the error if the runner was stopped or its queue is full.
+func (r *jobRunner) run(id string, priority dto.JobPriority, task func(ctx context.Context)) error {
+	r.once.Do(r.startWorkers)
+
+	r.mut.Lock()
+	defer r.mut.Unlock()
+
+	if r.flagStopped {
+		return services.ErrJobsStopped
+	} else if r.queued >= r.queueSize {
+		jobsMetric.Add("rejected", 1)
+		return fmt.Errorf("%w: %d jobs are queued", services.ErrJobsQueueFull, r.queued)
+	}
+
+	priority = r.getPriority(priority)
+	r.queues[priority] = append(r.queues[priority], jobTask{id: id, task: task, queuedAt: time.Now()})
+	r.queued++
+
+	jobsMetric.Add("queued", 1)
+	jobsMetric.Add("queued_"+string(priority), 1)
+
+	r.cond.Signal()
+
+	return nil
+}
+
+// remove takes the task of the job with the id out of the queue so it frees the queue's place at once.
+// It returns false if the task isn't queued: for example, it was already taken by the worker.
+func (r *jobRunner) remove(id string) bool {
+	r.mut.Lock()
+	defer r.mut.Unlock()
+
+	for _, priority := range jobsPriorities {
+		for i, task := range r.queues[priority] {
+			if task.id != id {
+				continue
+			}
+			r.queues[priority] = append(r.queues[priority][:i:i], r.queues[priority][i+1:]...)
+			r.queued--
+
+			jobsMetric.Add("queued", -1)
+			jobsMetric.Add("queued_"+string(priority), -1)
+
+			return true
+		}
+	}
+
+	return false
+}
+
+// startWorkers starts the runner's workers.
+func (r *jobRunner) startWorkers() {
+	for range r.workers {
+		r.wg.Add(1)
+		go r.work()
+	}
+}
+
+// next returns the queued task with the highest priority: it waits until the task is queued.
+// It returns false if the runner was stopped and its queue is empty.
+func (r *jobRunner) next() (jobTask, bool) {
+	r.mut.Lock()
+	defer r.mut.Unlock()
+
+	for r.queued == 0 && !r.flagStopped {
+		r.cond.Wait()
+	}
+
+	for _, priority := range jobsPriorities {
+		if len(r.queues[priority]) == 0 {
+			continue
+		}
+		task := r.queues[priority][0]
+		r.queues[priority] = r.queues[priority][1:]
+		r.queued--
+
+		jobsMetric.Add("queued", -1)
+		jobsMetric.Add("queued_"+string(priority), -1)
+
+		return task, true
+	}
+
+	return jobTask{}, false
+}
+
+// work processes the queued tasks until the runner is stopped.
+func (r *jobRunner) work() {
+	defer r.wg.Done()
+
+	for {
+		task, flagExist := r.next()
+
+		if !flagExist {
+			return
+		}
+
+		wait := time.Since(task.queuedAt).Milliseconds()
+
+		jobsMetric.Add("waited", 1)
+		jobsMetric.Add("wait_ms", wait)
+		jobsMetric.Set("last_wait_ms", intVar(wait))
+
+		jobsMetric.Add("running", 1)
+		r.process(task)
+		jobsMetric.Add("running", -1)
+	}
+}
+
+// process runs the task with the job's context.
+func (r *jobRunner) process(task jobTask) {
+	ctx, cancel := context.WithTimeoutCause(r.ctx, r.deadline, services.ErrJobDeadline)
+	defer cancel()
+
+	task.task(ctx)
+}
+
+// shutdown stops accepting the new jobs and waits for the queued and the running ones until the ctx is done:
+// after it the rest jobs are cancelled and the error is returned.
+func (r *jobRunner) shutdown(ctx context.Context) error {
+	r.once.Do(r.startWorkers)
+
+	r.mut.Lock()
+	r.flagStopped = true
+	r.cond.Broadcast()
+	r.mut.Unlock()
+
+	done := make(chan struct{})
+
+	go func() {
+		r.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+
+	case <-ctx.Done():
+		r.cancel(services.ErrJobCancelled)
+		<-done
+
+		return fmt.Errorf("%w: %v", services.ErrJobCancelled, ctx.Err())
+	}
+}
+
+// intVar returns the expvar's value of the number.
+func intVar(value int64) *expvar.Int {
+	metric := new(expvar.Int)
+	metric.Set(value)
+
+	return metric
+}
@@ -0,0 +1,156 @@
+package filter
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/MaKcm14/price-service/internal/entities/dto"
+	"github.com/MaKcm14/price-service/internal/services"
+)
+
+func TestJobRunnerCases(t *testing.T) {
+	t.Run("Positive Case: the running jobs are drained", func(t *testing.T) {
+		runner := newJobRunner(time.Second)
+		flagDone := false
+
+		err := runner.run("test-job", dto.NormalPriority, func(ctx context.Context) {
+			time.Sleep(20 * time.Millisecond)
+			flagDone = true
+		})
+
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+
+		assert.NoError(t, runner.shutdown(ctx))
+		assert.True(t, flagDone)
+	})
+
+	t.Run("Negative Case: the jobs that aren't drained in time are cancelled", func(t *testing.T) {
+		runner := newJobRunner(time.Minute)
+		var cause error
+
+		runner.run("test-job", dto.NormalPriority, func(ctx context.Context) {
+			<-ctx.Done()
+			cause = context.Cause(ctx)
+		})
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+
+		assert.ErrorIs(t, runner.shutdown(ctx), services.ErrJobCancelled)
+		assert.ErrorIs(t, cause, services.ErrJobCancelled)
+	})
+
+	t.Run("Negative Case: the stopped runner doesn't accept the jobs", func(t *testing.T) {
+		runner := newJobRunner(time.Second)
+
+		assert.NoError(t, runner.shutdown(context.Background()))
+		assert.ErrorIs(t, runner.run("test-job", dto.NormalPriority, func(ctx context.Context) {}), services.ErrJobsStopped)
+	})
+
+	t.Run("Extreme Case: the job's deadline is exceeded", func(t *testing.T) {
+		runner := newJobRunner(20 * time.Millisecond)
+		cause := make(chan error, 1)
+
+		runner.run("test-job", dto.NormalPriority, func(ctx context.Context) {
+			<-ctx.Done()
+			cause <- context.Cause(ctx)
+		})
+
+		select {
+		case err := <-cause:
+			assert.ErrorIs(t, err, services.ErrJobDeadline)
+
+		case <-time.After(time.Second):
+			t.Fatal("the job's deadline hasn't been exceeded in time")
+		}
+	})
+}
+
+func TestJobRunnerQueueCases(t *testing.T) {
+	t.Run("Positive Case: the jobs are taken by their priorities", func(t *testing.T) {
+		runner := newJobRunner(time.Second)
+		runner.workers = 1
+
+		release := make(chan struct{})
+		order := make([]dto.JobPriority, 0, 3)
+
+		// the only worker is busy while the jobs are queued.
+		runner.run("test-job", dto.NormalPriority, func(ctx context.Context) {
+			<-release
+		})
+
+		for _, priority := range []dto.JobPriority{dto.LowPriority, dto.NormalPriority, dto.HighPriority} {
+			runner.run("test-job", priority, func(ctx context.Context) {
+				order = append(order, priority)
+			})
+		}
+		close(release)
+
+		assert.NoError(t, runner.shutdown(context.Background()))
+		assert.Equal(t, []dto.JobPriority{dto.HighPriority, dto.NormalPriority, dto.LowPriority}, order)
+	})
+
+	t.Run("Negative Case: the full queue rejects the jobs", func(t *testing.T) {
+		runner := newJobRunner(time.Second)
+		runner.workers = 1
+		runner.queueSize = 1
+
+		release := make(chan struct{})
+		started := make(chan struct{})
+
+		runner.run("test-job", dto.NormalPriority, func(ctx context.Context) {
+			close(started)
+			<-release
+		})
+		<-started
+
+		assert.NoError(t, runner.run("test-job", dto.HighPriority, func(ctx context.Context) {}))
+		assert.ErrorIs(t, runner.run("test-job", dto.HighPriority, func(ctx context.Context) {}), services.ErrJobsQueueFull)
+
+		close(release)
+		assert.NoError(t, runner.shutdown(context.Background()))
+	})
+
+	t.Run("Positive Case: the removed task frees its place in the queue", func(t *testing.T) {
+		runner := newJobRunner(time.Second)
+		runner.workers = 1
+		runner.queueSize = 1
+
+		release := make(chan struct{})
+		started := make(chan struct{})
+		flagRun := false
+
+		runner.run("running-job", dto.NormalPriority, func(ctx context.Context) {
+			close(started)
+			<-release
+		})
+		<-started
+
+		runner.run("queued-job", dto.LowPriority, func(ctx context.Context) {
+			flagRun = true
+		})
+
+		assert.True(t, runner.remove("queued-job"))
+		assert.False(t, runner.remove("queued-job"))
+		assert.NoError(t, runner.run("next-job", dto.NormalPriority, func(ctx context.Context) {}))
+
+		close(release)
+		assert.NoError(t, runner.shutdown(context.Background()))
+		assert.False(t, flagRun)
+	})
+
+	t.Run("Extreme Case: the unknown priority is the normal one", func(t *testing.T) {
+		runner := newJobRunner(time.Second)
+
+		assert.Equal(t, dto.NormalPriority, runner.getPriority(""))
+		assert.Equal(t, dto.LowPriority, runner.getPriority(dto.LowPriority))
+	})
+}
@@ -3,8 +3,6 @@ package services
 import (
 	"context"
 
-	"github.com/labstack/echo/v4"
-
 	"github.com/MaKcm14/price-service/internal/entities/dto"
 	"github.com/MaKcm14/price-service/pkg/entities"
 )
@@ -12,7 +10,8 @@ import (
 type (
 	Driver interface {
 		Closer
-		NewContext() context.Context
+		Acquire(ctx context.Context) (context.Context, context.CancelFunc, error)
+		Stats() entities.DriverStats
 	}
 
 	Closer interface {
@@ -21,21 +20,27 @@ type (
 
 	AsyncWriter interface {
 		Closer
-		SendProductsMessage(products []entities.ProductSample, request dto.ProductRequest)
+		SendProductsMessage(response dto.FilterResponse, request dto.ProductRequest)
+		SendCancelledMessage(job dto.Job, request dto.ProductRequest)
 	}
 
 	CommonParser interface {
-		GetProducts(ctx echo.Context, request dto.ProductRequest) (entities.ProductSample, error)
+		GetProducts(ctx context.Context, request dto.ProductRequest) (entities.ProductSample, error)
 	}
 
 	PriceParser interface {
-		GetProductsWithPriceRange(ctx echo.Context, request dto.ProductRequest) (entities.ProductSample, error)
-		GetProductsWithExactPrice(ctx echo.Context, request dto.ProductRequest) (entities.ProductSample, error)
-		GetProductsWithBestPrice(ctx echo.Context, request dto.ProductRequest) (entities.ProductSample, error)
+		GetProductsWithPriceRange(ctx context.Context, request dto.ProductRequest) (entities.ProductSample, error)
+		GetProductsWithExactPrice(ctx context.Context, request dto.ProductRequest) (entities.ProductSample, error)
+		GetProductsWithBestPrice(ctx context.Context, request dto.ProductRequest) (entities.ProductSample, error)
 	}
 
 	ApiInteractor interface {
 		PriceParser
 		CommonParser
 	}
+
+	// PagesLimiter defines the markets' apis that can get several pages of the sample simultaneously.
+	PagesLimiter interface {
+		GetPagesConcurrency(request dto.ProductRequest) int
+	}
 )
@@ -6,8 +6,52 @@ const (
 	NotExists
 )
 
+const (
+	MarketStatusOK      MarketStatusType = "ok"
+	MarketStatusFailed  MarketStatusType = "failed"
+	MarketStatusTimeout MarketStatusType = "timeout"
+	MarketStatusBlocked MarketStatusType = "blocked"
+
+	// MarketStatusUnsupported means that the market can't process the request's params:
+	// the market isn't requested at all.
+	MarketStatusUnsupported MarketStatusType = "unsupported"
+)
+
 type Market int
 
+// String returns the name of the market.
+func (m Market) String() string {
+	if m == Wildberries {
+		return "Wildberries"
+	} else if m == MegaMarket {
+		return "MegaMarket"
+	}
+	return ""
+}
+
+type MarketStatusType string
+
+// MarketStatus defines the result of the interaction with the concrete market.
+type MarketStatus struct {
+	Market    string           `json:"market"`
+	Status    MarketStatusType `json:"status"`
+	ErrorCode string           `json:"error_code,omitempty"`
+	ElapsedMs int64            `json:"elapsed_ms"`
+	Discarded int              `json:"discarded"`
+}
+
+// DriverStats defines the state of the pull of the browser's tabs.
+type DriverStats struct {
+	Browsers  int   `json:"browsers"`
+	Tabs      int   `json:"tabs"`
+	Idle      int   `json:"idle"`
+	InUse     int   `json:"in_use"`
+	Waiting   int   `json:"waiting"`
+	Acquired  int64 `json:"acquired"`
+	Recycled  int64 `json:"recycled"`
+	Exhausted int64 `json:"exhausted"`
+}
+
 // MarketView defines the data structure of the concrete market.
 type MarketView struct {
 	MarketName  string `json:"name"`
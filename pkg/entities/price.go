@@ -1,9 +1,17 @@
 package entities
 
+// PriceRange defines the range of the product's prices when its variants have got the different prices.
+type PriceRange struct {
+	Min int `json:"min"`
+	Max int `json:"max"`
+}
+
 type Price struct {
 	BasePrice     int `json:"base_price"`
 	DiscountPrice int `json:"discount_price"`
 	Discount      int `json:"discount"`
+
+	Range *PriceRange `json:"range,omitempty"`
 }
 
 func NewPrice(basePrice, discPrice int) Price {
@@ -20,3 +28,12 @@ func NewPrice(basePrice, discPrice int) Price {
 		Discount:      discount,
 	}
 }
+
+// BestPriceSummary defines the comparison of the markets' minimal prices.
+type BestPriceSummary struct {
+	Market           string         `json:"market"`
+	MinPrice         int            `json:"min_price"`
+	MarketsMinPrices map[string]int `json:"markets_min_prices"`
+	Spread           int            `json:"spread"`
+	SpreadPercent    float64        `json:"spread_percent"`
+}
@@ -4,19 +4,69 @@ const (
 	RUB Currency = "rub"
 )
 
+// NoImageLink is the marker of the product which image couldn't be found in the market's page.
+const NoImageLink = "no_image"
+
+const (
+	LoadingStopEnough   LoadingStopReason = "enough_cards"
+	LoadingStopStable   LoadingStopReason = "stable"
+	LoadingStopDeadline LoadingStopReason = "deadline"
+)
+
+const (
+	InStock             Availability = "in_stock"
+	OutOfStock          Availability = "out_of_stock"
+	Preorder            Availability = "preorder"
+	UnknownAvailability Availability = "unknown"
+)
+
 type Currency string
 
+// Availability defines the product's stock status in the market.
+type Availability string
+
+// ProductDelivery defines the market's estimate of the product's delivery:
+// the hours of the delivery or its date.
+type ProductDelivery struct {
+	Hours int    `json:"hours,omitempty"`
+	Date  string `json:"date,omitempty"`
+}
+
+// LoadingStopReason defines the reason why the loading of the market's page was stopped.
+type LoadingStopReason string
+
+// PageLoading defines the result of the loading of the market's page with the products' cards.
+type PageLoading struct {
+	Cards      int               `json:"cards"`
+	StopReason LoadingStopReason `json:"stop_reason"`
+}
+
 type ProductLink struct {
 	URL       string `json:"url"`
 	ImageLink string `json:"image_link"`
 }
 
+// PriceDeviation defines the deviation of the product's price from the client's exact price.
+type PriceDeviation struct {
+	Absolute int     `json:"absolute"`
+	Percent  float64 `json:"percent"`
+}
+
 type Product struct {
 	Name     string      `json:"name"`
 	Brand    string      `json:"brand"`
 	Price    Price       `json:"price"`
 	Links    ProductLink `json:"related_links"`
 	Supplier string      `json:"supplier"`
+	Market   string      `json:"market,omitempty"`
+
+	Availability Availability `json:"availability"`
+
+	// Quantity and Delivery are set only if the market provides them.
+	Quantity *int             `json:"quantity,omitempty"`
+	Delivery *ProductDelivery `json:"delivery,omitempty"`
+
+	Deviation *PriceDeviation `json:"deviation,omitempty"`
 }
 
 // ProductSample defines the sample of the products from the one market.
@@ -25,21 +75,19 @@ type ProductSample struct {
 	SampleLink string    `json:"main_products_sample"`
 	Market     string    `json:"market"`
 	Currency   Currency  `json:"currency"`
-}
 
-func NewProductSample(products []Product, sampleLink string, sampleMarket Market) ProductSample {
-	var market string
+	// Loading is set only if the market's page was loaded through the browser.
+	Loading *PageLoading `json:"loading,omitempty"`
 
-	if sampleMarket == Wildberries {
-		market = "Wildberries"
-	} else if sampleMarket == MegaMarket {
-		market = "MegaMarket"
-	}
+	// Rejected is the amount of the market's products that didn't pass the validation by the reasons.
+	Rejected map[string]int `json:"rejected,omitempty"`
+}
 
+func NewProductSample(products []Product, sampleLink string, sampleMarket Market) ProductSample {
 	return ProductSample{
 		Products:   products,
 		SampleLink: sampleLink,
-		Market:     market,
+		Market:     sampleMarket.String(),
 		Currency:   RUB,
 	}
 }
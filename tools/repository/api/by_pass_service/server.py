@@ -1,10 +1,19 @@
-from flask import Flask, request
+import json
+from flask import Flask, request, jsonify
 from megamarket import MegaMarketAPI
 
+PROTOCOL_VERSION: int = 1
+
 app = Flask(__name__)
 
+
+def error_response(code: str, message: str, status: int):
+    return jsonify({"version": PROTOCOL_VERSION, "error": {"code": code, "message": message}}), status
+
+
 # POST-handle: client sends the next JSON-object:
 # {
+#   "version": protocol_version,
 #   "query": "query_text",
 #   "sample": "sample_num",
 #   "sort": "sort_num",
@@ -16,22 +25,33 @@ app = Flask(__name__)
 #   }
 # }
 # which defines the products' request query.
-# Response is the JSON-object of the response from megamarket service.
+# Response is the JSON-object:
+# {"version": protocol_version, "data": megamarket_service_response}
+# or the error's JSON-object:
+# {"version": protocol_version, "error": {"code": "error_code", "message": "error_description"}}
+# where the error's code is "unsupported_version", "bad_request" (400), "upstream_overflow" (502) or "internal" (500).
 @app.route('/mmarket', methods=['POST'])
 def get_mmarket_products():
-    body = request.get_json()
+    body = request.get_json(silent=True)
+
     try:
+        if body.get("version") != PROTOCOL_VERSION:
+            return error_response("unsupported_version",
+                f"the protocol's version {body.get('version')} isn't supported: use {PROTOCOL_VERSION}", 400)
+
         getter = MegaMarketAPI(body)
-        return getter.get_products_json(), 200
 
-    except (AttributeError,TypeError):
-        return str({"error": "error of the request's structure"}), 400
+    except (AttributeError, TypeError, KeyError, ValueError):
+        return error_response("bad_request", "error of the request's structure", 400)
+
+    try:
+        return jsonify({"version": PROTOCOL_VERSION, "data": json.loads(getter.get_products_json())}), 200
 
     except OverflowError as excp:
-        return str({"error": excp.args[0]}), 502
+        return error_response("upstream_overflow", str(excp.args[0]), 502)
 
     except BaseException as excp:
-        return str({"error": excp.args[0]}), 500
+        return error_response("internal", str(excp.args[0]) if excp.args else repr(excp), 500)
 
 
 if __name__ == '__main__':